@@ -0,0 +1,318 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+// AgentReference represents a subset detail of a build agent
+type AgentReference struct {
+	ID     int    `json:"id,omitempty" xml:"id"`
+	Name   string `json:"name,omitempty" xml:"name"`
+	TypeID int    `json:"typeId,omitempty" xml:"typeId"`
+	Href   string `json:"href,omitempty" xml:"href"`
+	WebURL string `json:"webUrl,omitempty" xml:"webUrl"`
+}
+
+// AgentReferences represents a collection of *AgentReference
+type AgentReferences struct {
+	Count    int               `json:"count,omitempty" xml:"count"`
+	Href     string            `json:"href,omitempty" xml:"href"`
+	NextHref string            `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*AgentReference `json:"agent"`
+}
+
+// Agent represents a build agent, with its state and the parameters it reports to the server
+type Agent struct {
+	ID             int                 `json:"id,omitempty" xml:"id"`
+	Name           string              `json:"name,omitempty" xml:"name"`
+	TypeID         int                 `json:"typeId,omitempty" xml:"typeId"`
+	Connected      bool                `json:"connected,omitempty" xml:"connected"`
+	Enabled        bool                `json:"enabled,omitempty" xml:"enabled"`
+	Authorized     bool                `json:"authorized,omitempty" xml:"authorized"`
+	UpToDate       bool                `json:"uptodate,omitempty" xml:"uptodate"`
+	IP             string              `json:"ip,omitempty" xml:"ip"`
+	Pool           *AgentPoolReference `json:"pool,omitempty"`
+	EnabledInfo    *AgentStatusInfo    `json:"enabledInfo,omitempty"`
+	AuthorizedInfo *AgentStatusInfo    `json:"authorizedInfo,omitempty"`
+	Properties     *Properties         `json:"properties,omitempty"`
+	Href           string              `json:"href,omitempty" xml:"href"`
+	WebURL         string              `json:"webUrl,omitempty" xml:"webUrl"`
+}
+
+// Parameters returns all the parameters reported by the agent, as used to evaluate agent requirements
+func (a *Agent) Parameters() map[string]string {
+	if a.Properties == nil {
+		return make(map[string]string)
+	}
+	return a.Properties.Map()
+}
+
+// Environment returns the environment variables reported by the agent, without their "env." prefix
+func (a *Agent) Environment() map[string]string {
+	return a.parametersWithPrefix("env.")
+}
+
+// SystemProperties returns the system properties reported by the agent, such as "agent.name" or "teamcity.agent.jvm.os.name", without their "system." prefix
+func (a *Agent) SystemProperties() map[string]string {
+	return a.parametersWithPrefix("system.")
+}
+
+func (a *Agent) parametersWithPrefix(prefix string) map[string]string {
+	out := make(map[string]string)
+	if a.Properties == nil {
+		return out
+	}
+	for _, p := range a.Properties.Items {
+		if strings.HasPrefix(p.Name, prefix) {
+			out[strings.TrimPrefix(p.Name, prefix)] = p.Value
+		}
+	}
+	return out
+}
+
+// AgentStatusInfo is the enabled or authorized status of an agent, with the comment left by whoever changed it last
+type AgentStatusInfo struct {
+	Status  bool          `json:"status" xml:"status"`
+	Comment *BuildComment `json:"comment,omitempty"`
+
+	// StatusSwitchTime is when the status switches back automatically, in TimeFormat. Only used for enabledInfo.
+	StatusSwitchTime string `json:"statusSwitchTime,omitempty" xml:"statusSwitchTime"`
+}
+
+// AgentFilter restricts the agents returned by AgentService.List. Nil and empty fields are not used for filtering.
+type AgentFilter struct {
+	Connected  *bool
+	Authorized *bool
+	Enabled    *bool
+
+	// PoolID lists only agents of the given pool. The default pool has id 0.
+	PoolID *int
+
+	// Count limits the number of agents returned
+	Count int
+}
+
+func (f *AgentFilter) locator() Locator {
+	b := f.dimensions()
+	if f.Count > 0 {
+		b.Count(f.Count)
+	}
+	return b.Locator()
+}
+
+// dimensions returns the locator of the filter without its count, which iterators replace with the page size
+func (f *AgentFilter) dimensions() *LocatorBuilder {
+	b := NewLocatorBuilder()
+	if f.Connected != nil {
+		b.Dimension("connected", strconv.FormatBool(*f.Connected))
+	}
+	if f.Authorized != nil {
+		b.Dimension("authorized", strconv.FormatBool(*f.Authorized))
+	}
+	if f.Enabled != nil {
+		b.Dimension("enabled", strconv.FormatBool(*f.Enabled))
+	}
+	if f.PoolID != nil {
+		b.Pool(NewLocatorBuilder().ID(strconv.Itoa(*f.PoolID)))
+	}
+	if len(b.Dimensions()) == 0 {
+		return b
+	}
+	// Without this, dimensions left unset fall back to the server's default of connected and authorized agents only
+	return b.Dimension("defaultFilter", "false")
+}
+
+// AgentService has operations for inspecting and administering build agents
+type AgentService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+}
+
+func newAgentService(base *sling.Sling, httpClient *http.Client) *AgentService {
+	sling := base.New().Path("agents/")
+	return &AgentService{
+		sling:      sling,
+		httpClient: httpClient,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+	}
+}
+
+// List returns the agents matching filter. filter can be nil, which lists the connected and authorized agents.
+func (s *AgentService) List(filter *AgentFilter) ([]*AgentReference, error) {
+	return s.ListWithContext(context.Background(), filter)
+}
+
+// ListWithContext returns the agents matching filter, bound to ctx. filter can be nil.
+func (s *AgentService) ListWithContext(ctx context.Context, filter *AgentFilter) ([]*AgentReference, error) {
+	path := ""
+	if filter != nil {
+		if locator := filter.locator(); locator != "" {
+			path = "?locator=" + locator.String()
+		}
+	}
+
+	var out AgentReferences
+	err := s.restHelper.get(ctx, path, &out, "agents")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate walks the agents matching filter, fetching pageSize agents per request. filter can be nil, which lists the connected
+// and authorized agents. filter.Count, when set, limits the number of agents visited. Use DefaultPageSize when pageSize is not positive.
+func (s *AgentService) Iterate(filter *AgentFilter, pageSize int) *AgentIterator {
+	return s.IterateWithContext(context.Background(), filter, pageSize)
+}
+
+// IterateWithContext walks the agents matching filter page by page, bound to ctx. filter can be nil.
+func (s *AgentService) IterateWithContext(ctx context.Context, filter *AgentFilter, pageSize int) *AgentIterator {
+	var locator Locator
+	limit := 0
+	if filter != nil {
+		locator, limit = filter.dimensions().Locator(), filter.Count
+	}
+	return newAgentIterator(ctx, s.restHelper, "?locator="+pagedLocator(locator, pageSize).String(), limit)
+}
+
+// GetByID returns the details of an agent, including its parameters and environment, by its id
+func (s *AgentService) GetByID(id int) (*Agent, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext returns the details of an agent by its id, bound to ctx
+func (s *AgentService) GetByIDWithContext(ctx context.Context, id int) (*Agent, error) {
+	return s.getByLocator(ctx, LocatorIDInt(id))
+}
+
+// GetFields returns an agent by its id, with only the selected fields
+func (s *AgentService) GetFields(id int, fields Fields) (*Agent, error) {
+	return s.GetFieldsWithContext(context.Background(), id, fields)
+}
+
+// GetFieldsWithContext returns an agent by its id, with only the selected fields, bound to ctx
+func (s *AgentService) GetFieldsWithContext(ctx context.Context, id int, fields Fields) (*Agent, error) {
+	return s.getByLocator(ctx, LocatorIDInt(id)+Locator(fields.query()))
+}
+
+// GetByName returns the details of an agent, including its parameters and environment, by its name
+func (s *AgentService) GetByName(name string) (*Agent, error) {
+	return s.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext returns the details of an agent by its name, bound to ctx
+func (s *AgentService) GetByNameWithContext(ctx context.Context, name string) (*Agent, error) {
+	return s.getByLocator(ctx, LocatorName(name))
+}
+
+func (s *AgentService) getByLocator(ctx context.Context, locator Locator) (*Agent, error) {
+	var out Agent
+	err := s.restHelper.get(ctx, locator.String(), &out, "agent")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// Authorize allows the agent with given id to run builds. comment can be empty.
+func (s *AgentService) Authorize(id int, comment string) error {
+	return s.AuthorizeWithContext(context.Background(), id, comment)
+}
+
+// AuthorizeWithContext allows the agent with given id to run builds, bound to ctx. comment can be empty.
+func (s *AgentService) AuthorizeWithContext(ctx context.Context, id int, comment string) error {
+	return s.setStatus(ctx, id, "authorizedInfo", newAgentStatusInfo(true, comment))
+}
+
+// Unauthorize revokes the authorization of the agent with given id, which also frees its license slot. comment can be empty.
+func (s *AgentService) Unauthorize(id int, comment string) error {
+	return s.UnauthorizeWithContext(context.Background(), id, comment)
+}
+
+// UnauthorizeWithContext revokes the authorization of the agent with given id, bound to ctx. comment can be empty.
+func (s *AgentService) UnauthorizeWithContext(ctx context.Context, id int, comment string) error {
+	return s.setStatus(ctx, id, "authorizedInfo", newAgentStatusInfo(false, comment))
+}
+
+// Enable lets the agent with given id pick up builds again. comment can be empty.
+func (s *AgentService) Enable(id int, comment string) error {
+	return s.EnableWithContext(context.Background(), id, comment)
+}
+
+// EnableWithContext lets the agent with given id pick up builds again, bound to ctx. comment can be empty.
+func (s *AgentService) EnableWithContext(ctx context.Context, id int, comment string) error {
+	return s.setStatus(ctx, id, "enabledInfo", newAgentStatusInfo(true, comment))
+}
+
+// Disable stops the agent with given id from picking up new builds. comment can be empty.
+// When reenableAt is not the zero time, the server enables the agent again at that time.
+func (s *AgentService) Disable(id int, comment string, reenableAt time.Time) error {
+	return s.DisableWithContext(context.Background(), id, comment, reenableAt)
+}
+
+// DisableWithContext stops the agent with given id from picking up new builds, bound to ctx. comment can be empty.
+func (s *AgentService) DisableWithContext(ctx context.Context, id int, comment string, reenableAt time.Time) error {
+	info := newAgentStatusInfo(false, comment)
+	if !reenableAt.IsZero() {
+		info.StatusSwitchTime = reenableAt.Format(TimeFormat)
+	}
+	return s.setStatus(ctx, id, "enabledInfo", info)
+}
+
+func newAgentStatusInfo(status bool, comment string) *AgentStatusInfo {
+	info := &AgentStatusInfo{Status: status}
+	if comment != "" {
+		info.Comment = &BuildComment{Text: comment}
+	}
+	return info
+}
+
+func (s *AgentService) setStatus(ctx context.Context, id int, info string, status *AgentStatusInfo) error {
+	var out AgentStatusInfo
+	return s.restHelper.put(ctx, fmt.Sprintf("%s/%s", LocatorIDInt(id), info), status, &out, "agent "+info)
+}
+
+// MoveToPool moves the agent with given id to the agent pool with given id and returns the new pool
+func (s *AgentService) MoveToPool(id int, poolID int) (*AgentPoolReference, error) {
+	return s.MoveToPoolWithContext(context.Background(), id, poolID)
+}
+
+// MoveToPoolWithContext moves the agent with given id to the agent pool with given id, bound to ctx
+func (s *AgentService) MoveToPoolWithContext(ctx context.Context, id int, poolID int) (*AgentPoolReference, error) {
+	if poolID < 0 {
+		return nil, errors.New("poolID must not be negative")
+	}
+
+	var out AgentPoolReference
+	// The default pool has id 0, so the id is sent explicitly instead of through AgentPoolReference
+	request := struct {
+		ID int `json:"id" xml:"id"`
+	}{ID: poolID}
+	err := s.restHelper.put(ctx, fmt.Sprintf("%s/pool", LocatorIDInt(id)), request, &out, "agent pool")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// Delete removes the agent with given id from the server. Only disconnected agents can be deleted.
+func (s *AgentService) Delete(id int) error {
+	return s.DeleteWithContext(context.Background(), id)
+}
+
+// DeleteWithContext removes the agent with given id from the server, bound to ctx
+func (s *AgentService) DeleteWithContext(ctx context.Context, id int) error {
+	return s.restHelper.delete(ctx, LocatorIDInt(id).String(), "agent")
+}
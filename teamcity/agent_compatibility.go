@@ -0,0 +1,111 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AgentCompatibilityReport lists which agents can run builds of a build configuration, and why the others cannot
+type AgentCompatibilityReport struct {
+	BuildTypeID  string
+	Compatible   []*AgentReference
+	Incompatible []*IncompatibleAgent
+}
+
+// IncompatibleAgent is an agent unable to run builds of a build configuration
+type IncompatibleAgent struct {
+	Agent *AgentReference
+
+	// Reason is the server's explanation, such as "Unmet requirements: env.JDK_11 exists"
+	Reason string
+
+	// UnmetRequirements are the agent requirements of the build configuration the agent does not satisfy
+	UnmetRequirements []*AgentRequirement
+}
+
+type agentCompatibility struct {
+	Compatible        bool                    `json:"compatible" xml:"compatible"`
+	Agent             *AgentReference         `json:"agent,omitempty"`
+	BuildType         *BuildTypeReference     `json:"buildType,omitempty"`
+	UnmetRequirements *agentUnmetRequirements `json:"unmetRequirements,omitempty"`
+}
+
+type agentUnmetRequirements struct {
+	Description  string              `json:"description,omitempty" xml:"description"`
+	Requirements []*AgentRequirement `json:"requirement,omitempty"`
+}
+
+type agentCompatibilitiesJSON struct {
+	Count int                   `json:"count,omitempty" xml:"count"`
+	Items []*agentCompatibility `json:"compatibility"`
+}
+
+// GetCompatibility reports which connected and authorized agents can run builds of the build configuration with given id,
+// with the unmet requirements of each agent that cannot
+func (s *AgentService) GetCompatibility(buildTypeID string) (*AgentCompatibilityReport, error) {
+	return s.GetCompatibilityWithContext(context.Background(), buildTypeID)
+}
+
+// GetCompatibilityWithContext reports which agents can run builds of the build configuration with given id, bound to ctx
+func (s *AgentService) GetCompatibilityWithContext(ctx context.Context, buildTypeID string) (*AgentCompatibilityReport, error) {
+	if buildTypeID == "" {
+		return nil, errors.New("buildTypeID is required")
+	}
+
+	compatible, err := s.listCompatible(ctx, "compatible", buildTypeID)
+	if err != nil {
+		return nil, err
+	}
+	incompatible, err := s.listCompatible(ctx, "incompatible", buildTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &AgentCompatibilityReport{
+		BuildTypeID: buildTypeID,
+		Compatible:  compatible,
+	}
+	for _, agent := range incompatible {
+		reason, err := s.unmetRequirements(ctx, agent, buildTypeID)
+		if err != nil {
+			return nil, err
+		}
+		out.Incompatible = append(out.Incompatible, reason)
+	}
+	return out, nil
+}
+
+func (s *AgentService) listCompatible(ctx context.Context, dimension string, buildTypeID string) ([]*AgentReference, error) {
+	locator := NewLocatorBuilder().Sub(dimension, NewLocatorBuilder().BuildType(NewLocatorBuilder().ID(buildTypeID))).Locator()
+
+	var out AgentReferences
+	err := s.restHelper.get(ctx, "?locator="+locator.String(), &out, dimension+" agents")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// unmetRequirements finds the build configuration among the ones the agent cannot run, as only that resource explains why
+func (s *AgentService) unmetRequirements(ctx context.Context, agent *AgentReference, buildTypeID string) (*IncompatibleAgent, error) {
+	var out agentCompatibilitiesJSON
+	err := s.restHelper.get(ctx, fmt.Sprintf("%s/incompatibleBuildTypes", LocatorIDInt(agent.ID)), &out, "agent incompatible build types")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IncompatibleAgent{Agent: agent}
+	for _, c := range out.Items {
+		if c.BuildType == nil || c.BuildType.ID != buildTypeID || c.UnmetRequirements == nil {
+			continue
+		}
+		result.Reason = c.UnmetRequirements.Description
+		result.UnmetRequirements = c.UnmetRequirements.Requirements
+		for _, r := range result.UnmetRequirements {
+			r.BuildTypeID = buildTypeID
+		}
+	}
+	return result, nil
+}
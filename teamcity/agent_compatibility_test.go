@@ -0,0 +1,57 @@
+package teamcity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AgentGetCompatibility(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Query().Get("locator") == "compatible:(buildType:(id:Proj_Build))":
+			w.Write([]byte(`{"count":1,"agent":[{"id":1,"name":"linux-1"}]}`))
+		case r.URL.Query().Get("locator") == "incompatible:(buildType:(id:Proj_Build))":
+			w.Write([]byte(`{"count":1,"agent":[{"id":2,"name":"windows-1"}]}`))
+		case r.URL.Path == "/httpAuth/app/rest/agents/id:2/incompatibleBuildTypes":
+			w.Write([]byte(`{"count":2,"compatibility":[
+				{"compatible":false,"buildType":{"id":"Other_Build"},"unmetRequirements":{"description":"Unmet requirements: docker.version exists"}},
+				{"compatible":false,"buildType":{"id":"Proj_Build"},"unmetRequirements":{"description":"Unmet requirements: teamcity.agent.jvm.os.name contains Linux",
+					"requirement":[{"id":"RQ_1","type":"contains","properties":{"property":[{"name":"property-name","value":"teamcity.agent.jvm.os.name"},{"name":"property-value","value":"Linux"}]}}]}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	actual, err := client.Agents.GetCompatibility("Proj_Build")
+
+	require.NoError(t, err)
+	require.Len(t, actual.Compatible, 1)
+	assert.Equal(t, "linux-1", actual.Compatible[0].Name)
+	require.Len(t, actual.Incompatible, 1)
+	assert.Equal(t, "windows-1", actual.Incompatible[0].Agent.Name)
+	assert.Equal(t, "Unmet requirements: teamcity.agent.jvm.os.name contains Linux", actual.Incompatible[0].Reason)
+	require.Len(t, actual.Incompatible[0].UnmetRequirements, 1)
+	requirement := actual.Incompatible[0].UnmetRequirements[0]
+	assert.Equal(t, Conditions.Contains, requirement.Condition)
+	assert.Equal(t, "teamcity.agent.jvm.os.name", requirement.Name())
+	assert.Equal(t, "Linux", requirement.Value())
+	assert.Equal(t, "Proj_Build", requirement.BuildTypeID)
+}
+
+func Test_AgentGetCompatibilityRequiresBuildType(t *testing.T) {
+	client, err := NewClientWithAddress(BasicAuth("admin", "admin"), "http://127.0.0.1:1", http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Agents.GetCompatibility("")
+
+	assert.Error(t, err)
+}
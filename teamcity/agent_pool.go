@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -11,6 +12,7 @@ import (
 type ListAgentPools struct {
 	Count      int                  `json:"count,omitempty" xml:"count"`
 	Href       string               `json:"href,omitempty" xml:"href"`
+	NextHref   string               `json:"nextHref,omitempty" xml:"nextHref"`
 	AgentPools []AgentPoolReference `json:"agentPool,omitempty" xml:"agentPool"`
 }
 
@@ -28,6 +30,7 @@ type AgentPool struct {
 	Name      string                       `json:"name,omitempty" xml:"name"`
 	MaxAgents *int                         `json:"maxAgents,omitempty" xml:"maxAgents"`
 	Projects  *AgentPoolProjectAssignments `json:"projects,omitempty" xml:"projects"`
+	Agents    *AgentReferences             `json:"agents,omitempty" xml:"agents"`
 }
 
 // CreateAgentPool contains information needed to create an Agent Pool
@@ -59,6 +62,11 @@ func newAgentPoolsService(base *sling.Sling, client *http.Client) *AgentPoolsSer
 
 // AssignProject assigns a Project to a Agent Pool
 func (s *AgentPoolsService) AssignProject(poolId int, projectId string) error {
+	return s.AssignProjectWithContext(context.Background(), poolId, projectId)
+}
+
+// AssignProjectWithContext assigns a Project to a Agent Pool, bound to ctx
+func (s *AgentPoolsService) AssignProjectWithContext(ctx context.Context, poolId int, projectId string) error {
 	var project struct {
 		ID string `json:"id" xml:"id"`
 	}
@@ -67,7 +75,7 @@ func (s *AgentPoolsService) AssignProject(poolId int, projectId string) error {
 	var out Project
 
 	locator := LocatorIDInt(poolId).String()
-	err := s.restHelper.post(fmt.Sprintf("%s/projects", locator), project, &out, "Agent Pool")
+	err := s.restHelper.post(ctx, fmt.Sprintf("%s/projects", locator), project, &out, "Agent Pool")
 	if err != nil {
 		return err
 	}
@@ -77,9 +85,14 @@ func (s *AgentPoolsService) AssignProject(poolId int, projectId string) error {
 
 // Create will create an Agent Pool - which must have a unique name
 func (s *AgentPoolsService) Create(pool CreateAgentPool) (*AgentPool, error) {
+	return s.CreateWithContext(context.Background(), pool)
+}
+
+// CreateWithContext will create an Agent Pool - which must have a unique name, bound to ctx
+func (s *AgentPoolsService) CreateWithContext(ctx context.Context, pool CreateAgentPool) (*AgentPool, error) {
 	var created AgentPool
 
-	err := s.restHelper.post("", pool, &created, "Agent Pool")
+	err := s.restHelper.post(ctx, "", pool, &created, "Agent Pool")
 	if err != nil {
 		return nil, err
 	}
@@ -89,8 +102,13 @@ func (s *AgentPoolsService) Create(pool CreateAgentPool) (*AgentPool, error) {
 
 // Delete will delete an Agent Pool based on it's ID
 func (s *AgentPoolsService) Delete(id int) error {
+	return s.DeleteWithContext(context.Background(), id)
+}
+
+// DeleteWithContext will delete an Agent Pool based on it's ID, bound to ctx
+func (s *AgentPoolsService) DeleteWithContext(ctx context.Context, id int) error {
 	locator := LocatorIDInt(id).String()
-	err := s.restHelper.delete(locator, "Agent Pool")
+	err := s.restHelper.delete(ctx, locator, "Agent Pool")
 	if err != nil {
 		return err
 	}
@@ -100,9 +118,14 @@ func (s *AgentPoolsService) Delete(id int) error {
 
 // Get will return an Agent Pool based on it's ID
 func (s *AgentPoolsService) GetByID(id int) (*AgentPool, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext will return an Agent Pool based on it's ID, bound to ctx
+func (s *AgentPoolsService) GetByIDWithContext(ctx context.Context, id int) (*AgentPool, error) {
 	var out AgentPool
 	locator := LocatorIDInt(id).String()
-	err := s.restHelper.get(locator, &out, "Agent Pool")
+	err := s.restHelper.get(ctx, locator, &out, "Agent Pool")
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +135,14 @@ func (s *AgentPoolsService) GetByID(id int) (*AgentPool, error) {
 
 // Get will return an Agent Pool based on it's Name
 func (s *AgentPoolsService) GetByName(name string) (*AgentPool, error) {
+	return s.GetByNameWithContext(context.Background(), name)
+}
+
+// GetByNameWithContext will return an Agent Pool based on it's Name, bound to ctx
+func (s *AgentPoolsService) GetByNameWithContext(ctx context.Context, name string) (*AgentPool, error) {
 	var out AgentPool
 	locator := LocatorName(name).String()
-	err := s.restHelper.get(locator, &out, "Agent Pool")
+	err := s.restHelper.get(ctx, locator, &out, "Agent Pool")
 	if err != nil {
 		return nil, err
 	}
@@ -124,8 +152,13 @@ func (s *AgentPoolsService) GetByName(name string) (*AgentPool, error) {
 
 // List returns all of the available Agent Pools
 func (s *AgentPoolsService) List() (*ListAgentPools, error) {
+	return s.ListWithContext(context.Background())
+}
+
+// ListWithContext returns all of the available Agent Pools, bound to ctx
+func (s *AgentPoolsService) ListWithContext(ctx context.Context) (*ListAgentPools, error) {
 	var out ListAgentPools
-	err := s.restHelper.get("", &out, "Agent Pools")
+	err := s.restHelper.get(ctx, "", &out, "Agent Pools")
 	if err != nil {
 		return nil, err
 	}
@@ -133,12 +166,27 @@ func (s *AgentPoolsService) List() (*ListAgentPools, error) {
 	return &out, nil
 }
 
+// Iterate walks all of the available Agent Pools, fetching pageSize pools per request. Use DefaultPageSize when pageSize is not positive.
+func (s *AgentPoolsService) Iterate(pageSize int) *AgentPoolIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
+
+// IterateWithContext walks all of the available Agent Pools page by page, bound to ctx
+func (s *AgentPoolsService) IterateWithContext(ctx context.Context, pageSize int) *AgentPoolIterator {
+	return newAgentPoolIterator(ctx, s.restHelper, "?locator="+pagedLocator("", pageSize).String())
+}
+
 // List returns all of the assigned Agent Pools for a specific Project
 func (s *AgentPoolsService) ListForProject(projectId string) (*ListAgentPools, error) {
+	return s.ListForProjectWithContext(context.Background(), projectId)
+}
+
+// ListForProjectWithContext returns all of the assigned Agent Pools for a specific Project, bound to ctx
+func (s *AgentPoolsService) ListForProjectWithContext(ctx context.Context, projectId string) (*ListAgentPools, error) {
 	var out ListAgentPools
 
 	locator := LocatorID(projectId) // /app/rest/agentPools/?locator=project:(id:_Root)
-	err := s.restHelper.get(fmt.Sprintf("?locator=project:(%s)", locator), &out, "Agent Pools")
+	err := s.restHelper.get(ctx, fmt.Sprintf("?locator=project:(%s)", locator), &out, "Agent Pools")
 	if err != nil {
 		return nil, err
 	}
@@ -148,10 +196,15 @@ func (s *AgentPoolsService) ListForProject(projectId string) (*ListAgentPools, e
 
 // UnassignProject unassigns a Project from a Agent Pool
 func (s *AgentPoolsService) UnassignProject(poolId int, projectId string) error {
+	return s.UnassignProjectWithContext(context.Background(), poolId, projectId)
+}
+
+// UnassignProjectWithContext unassigns a Project from a Agent Pool, bound to ctx
+func (s *AgentPoolsService) UnassignProjectWithContext(ctx context.Context, poolId int, projectId string) error {
 	poolLocator := LocatorIDInt(poolId).String()
 	projectLocator := LocatorID(projectId).String()
 	uri := fmt.Sprintf("%s/projects/%s", poolLocator, projectLocator)
-	err := s.restHelper.delete(uri, "Agent Pool")
+	err := s.restHelper.delete(ctx, uri, "Agent Pool")
 	if err != nil {
 		return err
 	}
@@ -1,9 +1,9 @@
 package teamcity
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	"github.com/dghubble/sling"
@@ -145,8 +145,9 @@ func NewAgentRequirement(condition string, paramName string, paramValue string)
 }
 
 type agentRequirementsJSON struct {
-	Count int32               `json:"count,omitempty" xml:"count"`
-	Items []*AgentRequirement `json:"agent-requirement"`
+	Count    int32               `json:"count,omitempty" xml:"count"`
+	NextHref string              `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*AgentRequirement `json:"agent-requirement"`
 }
 
 // AgentRequirementService provides operations for managing agent requirements for a build type
@@ -173,8 +174,13 @@ func newAgentRequirementService(buildTypeID string, client *http.Client, base *s
 
 //Create a new agent requirement for build type
 func (s *AgentRequirementService) Create(req *AgentRequirement) (*AgentRequirement, error) {
+	return s.CreateWithContext(context.Background(), req)
+}
+
+//CreateWithContext creates a new agent requirement for build type, bound to ctx
+func (s *AgentRequirementService) CreateWithContext(ctx context.Context, req *AgentRequirement) (*AgentRequirement, error) {
 	var created AgentRequirement
-	_, err := s.base.New().Post("").BodyJSON(req).ReceiveSuccess(&created)
+	err := s.restHelper.post(ctx, "", req, &created, "agent requirement")
 
 	if err != nil {
 		return nil, err
@@ -186,12 +192,13 @@ func (s *AgentRequirementService) Create(req *AgentRequirement) (*AgentRequireme
 
 //GetByID returns an agent requirement by its id
 func (s *AgentRequirementService) GetByID(id string) (*AgentRequirement, error) {
-	var out AgentRequirement
-	resp, err := s.base.New().Get(id).ReceiveSuccess(&out)
+	return s.GetByIDWithContext(context.Background(), id)
+}
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("404 Not Found - Trigger (id: %s) for buildTypeId (id: %s) was not found", id, s.BuildTypeID)
-	}
+//GetByIDWithContext returns an agent requirement by its id, bound to ctx
+func (s *AgentRequirementService) GetByIDWithContext(ctx context.Context, id string) (*AgentRequirement, error) {
+	var out AgentRequirement
+	err := s.restHelper.get(ctx, id, &out, "agent requirement")
 
 	if err != nil {
 		return nil, err
@@ -202,8 +209,13 @@ func (s *AgentRequirementService) GetByID(id string) (*AgentRequirement, error)
 
 //GetAll returns all agent requirements for a given build configuration
 func (s *AgentRequirementService) GetAll() ([]*AgentRequirement, error) {
+	return s.GetAllWithContext(context.Background())
+}
+
+//GetAllWithContext returns all agent requirements for a given build configuration, bound to ctx
+func (s *AgentRequirementService) GetAllWithContext(ctx context.Context) ([]*AgentRequirement, error) {
 	var aux agentRequirementsJSON
-	err := s.restHelper.get("", &aux, "agent requirements")
+	err := s.restHelper.get(ctx, "", &aux, "agent requirements")
 	if err != nil {
 		return nil, err
 	}
@@ -213,26 +225,23 @@ func (s *AgentRequirementService) GetAll() ([]*AgentRequirement, error) {
 	return aux.Items, nil
 }
 
-//Delete removes an agent requirement from the build configuration by its id
-func (s *AgentRequirementService) Delete(id string) error {
-	request, _ := s.base.New().Delete(id).Request()
-	response, err := s.httpClient.Do(request)
-	if err != nil {
-		return err
-	}
+//Iterate walks all agent requirements for a given build configuration, fetching pageSize requirements per request.
+//Use DefaultPageSize when pageSize is not positive.
+func (s *AgentRequirementService) Iterate(pageSize int) *AgentRequirementIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
 
-	defer response.Body.Close()
-	if response.StatusCode == 204 {
-		return nil
-	}
+//IterateWithContext walks all agent requirements for a given build configuration page by page, bound to ctx
+func (s *AgentRequirementService) IterateWithContext(ctx context.Context, pageSize int) *AgentRequirementIterator {
+	return newAgentRequirementIterator(ctx, s, "?locator="+pagedLocator("", pageSize).String())
+}
 
-	if response.StatusCode != 200 && response.StatusCode != 204 {
-		respData, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Error '%d' when deleting agent requirement: %s", response.StatusCode, string(respData))
-	}
+//Delete removes an agent requirement from the build configuration by its id
+func (s *AgentRequirementService) Delete(id string) error {
+	return s.DeleteWithContext(context.Background(), id)
+}
 
-	return nil
+//DeleteWithContext removes an agent requirement from the build configuration by its id, bound to ctx
+func (s *AgentRequirementService) DeleteWithContext(ctx context.Context, id string) error {
+	return s.restHelper.delete(ctx, id, "agent requirement")
 }
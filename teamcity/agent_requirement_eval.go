@@ -0,0 +1,249 @@
+package teamcity
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RequirementResult is the outcome of evaluating an AgentRequirement against a set of agent parameters
+type RequirementResult struct {
+	Requirement *AgentRequirement
+
+	// Passed is true when the agent satisfies the requirement. Disabled requirements always pass.
+	Passed bool
+
+	// Reason describes why the requirement failed, or is empty when it passed
+	Reason string
+}
+
+// RequirementResults is the outcome of evaluating a set of agent requirements, in the order they were given
+type RequirementResults []*RequirementResult
+
+// Passed reports whether the agent satisfies every requirement, meaning it is compatible with the build configuration
+func (r RequirementResults) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of the requirements the agent does not satisfy
+func (r RequirementResults) Failed() RequirementResults {
+	var out RequirementResults
+	for _, res := range r {
+		if !res.Passed {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// EvaluateAgentRequirements checks requirements, such as the ones returned by AgentRequirementService.GetAll, against the parameters
+// reported by an agent (see Agent.Parameters) without contacting the server. It fails on unknown conditions and invalid regular expressions.
+//
+// Conditions follow the server's semantics: numeric conditions fail for values that are not numbers, "matches" must match the whole value,
+// "ver-" conditions compare versions component by component and the negative conditions pass when the parameter is not reported at all.
+func EvaluateAgentRequirements(requirements []*AgentRequirement, parameters map[string]string) (RequirementResults, error) {
+	out := make(RequirementResults, 0, len(requirements))
+	for _, r := range requirements {
+		res, err := EvaluateAgentRequirement(r, parameters)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// EvaluateAgentRequirement checks a single requirement against the parameters reported by an agent. See EvaluateAgentRequirements.
+func EvaluateAgentRequirement(requirement *AgentRequirement, parameters map[string]string) (*RequirementResult, error) {
+	out := &RequirementResult{Requirement: requirement, Passed: true}
+	if requirement.Disabled != nil && *requirement.Disabled {
+		return out, nil
+	}
+
+	var name, expected string
+	if requirement.Properties != nil {
+		name = requirement.Name()
+		expected = requirement.Value()
+	}
+	if name == "" {
+		return nil, fmt.Errorf("requirement '%s' has no parameter name", requirement.ID)
+	}
+
+	actual, ok := parameters[name]
+	passed, err := evaluateCondition(requirement.Condition, actual, ok, expected)
+	if err != nil {
+		return nil, fmt.Errorf("requirement '%s': %w", requirement.ID, err)
+	}
+
+	if !passed {
+		out.Passed = false
+		switch {
+		case !ok:
+			out.Reason = fmt.Sprintf("parameter '%s' is not defined", name)
+		case requirement.Condition == Conditions.Exists:
+			out.Reason = fmt.Sprintf("parameter '%s' does not exist", name)
+		default:
+			out.Reason = fmt.Sprintf("parameter '%s' is '%s', which does not satisfy '%s %s'", name, actual, requirement.Condition, expected)
+		}
+	}
+	return out, nil
+}
+
+func evaluateCondition(condition string, actual string, defined bool, expected string) (bool, error) {
+	switch condition {
+	case Conditions.Exists:
+		return defined, nil
+	case Conditions.DoesNotEqual:
+		return !defined || actual != expected, nil
+	case Conditions.DoesNotContain:
+		return !defined || !strings.Contains(actual, expected), nil
+	case Conditions.DoesNotMatch:
+		matches, err := matchesWhole(expected, actual)
+		return !defined || !matches, err
+	}
+
+	if !defined {
+		// Still validate the condition, so a typo fails the same way for every agent
+		_, err := evaluateCondition(condition, "", true, expected)
+		return false, err
+	}
+
+	switch condition {
+	case Conditions.Equals:
+		return actual == expected, nil
+	case Conditions.StartsWith:
+		return strings.HasPrefix(actual, expected), nil
+	case Conditions.Contains:
+		return strings.Contains(actual, expected), nil
+	case Conditions.EndsWith:
+		return strings.HasSuffix(actual, expected), nil
+	case Conditions.Matches:
+		return matchesWhole(expected, actual)
+	case Conditions.MoreThan, Conditions.NoMoreThan, Conditions.LessThan, Conditions.NoLessThan:
+		a, aErr := strconv.ParseFloat(strings.TrimSpace(actual), 64)
+		e, eErr := strconv.ParseFloat(strings.TrimSpace(expected), 64)
+		if aErr != nil || eErr != nil {
+			return false, nil
+		}
+		return compareHolds(condition, compareFloats(a, e)), nil
+	case Conditions.VersionMoreThan, Conditions.VersionNoMoreThan, Conditions.VersionLessThan, Conditions.VersionNoLessThan:
+		return compareHolds(condition, compareVersions(actual, expected)), nil
+	}
+	return false, fmt.Errorf("unsupported condition '%s'", condition)
+}
+
+func matchesWhole(pattern string, value string) (bool, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false, fmt.Errorf("invalid regular expression '%s': %w", pattern, err)
+	}
+	return re.MatchString(value), nil
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareHolds reports whether cmp, the comparison of the actual value with the expected one, satisfies condition
+func compareHolds(condition string, cmp int) bool {
+	switch condition {
+	case Conditions.MoreThan, Conditions.VersionMoreThan:
+		return cmp > 0
+	case Conditions.NoMoreThan, Conditions.VersionNoMoreThan:
+		return cmp <= 0
+	case Conditions.LessThan, Conditions.VersionLessThan:
+		return cmp < 0
+	case Conditions.NoLessThan, Conditions.VersionNoLessThan:
+		return cmp >= 0
+	}
+	return false
+}
+
+// versionQualifiers are the pre-release qualifiers, lowest first. They sort before a release, so "1.0-rc1" < "1.0".
+var versionQualifiers = []string{"snapshot", "snap", "dev", "eap", "pre", "m", "alpha", "a", "beta", "b", "rc", "cr"}
+
+// compareVersions compares two versions component by component. Components are runs of digits, compared as numbers,
+// or runs of letters, where pre-release qualifiers sort before a release and other words sort after it.
+// Missing components count as zero, so "1.0" equals "1".
+func compareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		var x, y string
+		if i < len(ta) {
+			x = ta[i]
+		}
+		if i < len(tb) {
+			y = tb[i]
+		}
+		if cmp := compareVersionTokens(x, y); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+func versionTokens(v string) []string {
+	var out []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			out = append(out, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for _, r := range v {
+		switch {
+		case unicode.IsDigit(r):
+			if len(current) > 0 && !unicode.IsDigit(current[0]) {
+				flush()
+			}
+			current = append(current, r)
+		case unicode.IsLetter(r):
+			if len(current) > 0 && !unicode.IsLetter(current[0]) {
+				flush()
+			}
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// versionTokenRank orders the kinds of components: qualifiers, then missing or numeric components, then other words
+func versionTokenRank(t string) int {
+	if t == "" || unicode.IsDigit(rune(t[0])) {
+		return len(versionQualifiers)
+	}
+	for i, q := range versionQualifiers {
+		if t == q {
+			return i
+		}
+	}
+	return len(versionQualifiers) + 1
+}
+
+func compareVersionTokens(x, y string) int {
+	rx, ry := versionTokenRank(x), versionTokenRank(y)
+	if rx != ry {
+		return compareFloats(float64(rx), float64(ry))
+	}
+	if rx == len(versionQualifiers) {
+		// Numbers of any length, with missing components as zero
+		nx, ny := new(big.Int), new(big.Int)
+		nx.SetString("0"+x, 10)
+		ny.SetString("0"+y, 10)
+		return nx.Cmp(ny)
+	}
+	return strings.Compare(x, y)
+}
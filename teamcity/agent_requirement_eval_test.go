@@ -0,0 +1,105 @@
+package teamcity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EvaluateAgentRequirementConditions(t *testing.T) {
+	parameters := map[string]string{
+		"teamcity.agent.jvm.os.name":       "Linux",
+		"env.JAVA_VERSION":                 "11.0.2",
+		"docker.server.version":            "19.03.5",
+		"teamcity.agent.hardware.cpuCount": "8",
+		"system.release":                   "2.0-rc1",
+	}
+	cases := []struct {
+		condition string
+		name      string
+		value     string
+		expected  bool
+	}{
+		{Conditions.Exists, "docker.server.version", "", true},
+		{Conditions.Exists, "env.MISSING", "", false},
+		{Conditions.Equals, "teamcity.agent.jvm.os.name", "Linux", true},
+		{Conditions.Equals, "teamcity.agent.jvm.os.name", "linux", false},
+		{Conditions.Equals, "env.MISSING", "Linux", false},
+		{Conditions.DoesNotEqual, "teamcity.agent.jvm.os.name", "Windows", true},
+		{Conditions.DoesNotEqual, "env.MISSING", "Windows", true},
+		{Conditions.MoreThan, "teamcity.agent.hardware.cpuCount", "4", true},
+		{Conditions.MoreThan, "teamcity.agent.hardware.cpuCount", "8", false},
+		{Conditions.NoMoreThan, "teamcity.agent.hardware.cpuCount", "8", true},
+		{Conditions.LessThan, "teamcity.agent.hardware.cpuCount", "16", true},
+		{Conditions.NoLessThan, "teamcity.agent.hardware.cpuCount", "8.5", false},
+		{Conditions.MoreThan, "teamcity.agent.jvm.os.name", "1", false},
+		{Conditions.StartsWith, "teamcity.agent.jvm.os.name", "Lin", true},
+		{Conditions.Contains, "teamcity.agent.jvm.os.name", "nu", true},
+		{Conditions.DoesNotContain, "teamcity.agent.jvm.os.name", "Win", true},
+		{Conditions.DoesNotContain, "teamcity.agent.jvm.os.name", "in", false},
+		{Conditions.EndsWith, "teamcity.agent.jvm.os.name", "ux", true},
+		{Conditions.Matches, "teamcity.agent.jvm.os.name", "Lin.*", true},
+		{Conditions.Matches, "teamcity.agent.jvm.os.name", "Lin", false},
+		{Conditions.DoesNotMatch, "teamcity.agent.jvm.os.name", "Win.*", true},
+		{Conditions.DoesNotMatch, "env.MISSING", "Win.*", true},
+		{Conditions.VersionMoreThan, "env.JAVA_VERSION", "1.8", true},
+		{Conditions.VersionMoreThan, "env.JAVA_VERSION", "11.0.10", false},
+		{Conditions.VersionNoMoreThan, "env.JAVA_VERSION", "11.0.2.0", true},
+		{Conditions.VersionLessThan, "docker.server.version", "19.03.12", true},
+		{Conditions.VersionNoLessThan, "docker.server.version", "18.09", true},
+		{Conditions.VersionLessThan, "system.release", "2.0", true},
+		{Conditions.VersionMoreThan, "system.release", "2.0-beta3", true},
+		{Conditions.VersionMoreThan, "env.MISSING", "1.0", false},
+	}
+	for _, c := range cases {
+		requirement, err := NewAgentRequirement(c.condition, c.name, c.value)
+		require.NoError(t, err)
+
+		actual, err := EvaluateAgentRequirement(requirement, parameters)
+
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, actual.Passed, "%s %s %s", c.name, c.condition, c.value)
+		if !c.expected {
+			assert.NotEmpty(t, actual.Reason)
+		}
+	}
+}
+
+func Test_EvaluateAgentRequirementsResults(t *testing.T) {
+	linux, _ := NewAgentRequirement(Conditions.Equals, "teamcity.agent.jvm.os.name", "Linux")
+	docker, _ := NewAgentRequirement(Conditions.Exists, "docker.server.version", "")
+	disabled, _ := NewAgentRequirement(Conditions.Exists, "env.NEVER", "")
+	disabled.Disabled = NewTrue()
+
+	actual, err := EvaluateAgentRequirements([]*AgentRequirement{linux, docker, disabled}, map[string]string{"teamcity.agent.jvm.os.name": "Windows 10"})
+
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+	assert.False(t, actual.Passed())
+	require.Len(t, actual.Failed(), 2)
+	assert.Equal(t, "parameter 'teamcity.agent.jvm.os.name' is 'Windows 10', which does not satisfy 'equals Linux'", actual.Failed()[0].Reason)
+	assert.Equal(t, "parameter 'docker.server.version' is not defined", actual.Failed()[1].Reason)
+	assert.True(t, actual[2].Passed)
+}
+
+func Test_EvaluateAgentRequirementErrors(t *testing.T) {
+	invalid, _ := NewAgentRequirement(Conditions.Matches, "teamcity.agent.jvm.os.name", "(")
+	_, err := EvaluateAgentRequirement(invalid, map[string]string{"teamcity.agent.jvm.os.name": "Linux"})
+	assert.Error(t, err)
+
+	unknown, _ := NewAgentRequirement("is-shiny", "teamcity.agent.jvm.os.name", "yes")
+	_, err = EvaluateAgentRequirement(unknown, map[string]string{})
+	assert.Error(t, err)
+}
+
+func Test_CompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("1.0", "1"))
+	assert.Equal(t, 0, compareVersions("1.0.0", "1_0"))
+	assert.Equal(t, -1, compareVersions("1.9", "1.10"))
+	assert.Equal(t, -1, compareVersions("1.0-SNAPSHOT", "1.0-alpha"))
+	assert.Equal(t, -1, compareVersions("1.0-alpha", "1.0-beta"))
+	assert.Equal(t, -1, compareVersions("1.0-rc2", "1.0"))
+	assert.Equal(t, 1, compareVersions("1.0-sp1", "1.0"))
+	assert.Equal(t, 1, compareVersions("2019.2.1", "2019.2"))
+}
@@ -105,4 +105,5 @@ func TestAgentRequirement_Delete(t *testing.T) {
 
 	require.Error(err)
 	assert.Contains(err.Error(), "404")
+	assert.True(teamcity.IsNotFound(err))
 }
@@ -0,0 +1,90 @@
+package teamcity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AgentListFilters(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"count":1,"agent":[{"id":1,"name":"linux-1"}]}`, &requests))
+	defer done()
+
+	pool := 0
+	actual, err := client.Agents.List(&AgentFilter{Authorized: NewFalse(), PoolID: &pool})
+
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "linux-1", actual[0].Name)
+	assert.Equal(t, "/httpAuth/app/rest/agents/?locator=authorized%3Afalse%2Cpool%3A%28id%3A0%29%2CdefaultFilter%3Afalse", requests[0].URI)
+}
+
+func Test_AgentListCountOnlyKeepsDefaultFilter(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"count":0}`, &requests))
+	defer done()
+
+	_, err := client.Agents.List(&AgentFilter{Count: 10})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/httpAuth/app/rest/agents/?locator=count%3A10", requests[0].URI)
+}
+
+func Test_AgentGetByIDParameters(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"id":1,"name":"linux-1","connected":true,"enabled":false,
+		"pool":{"id":2,"name":"Release"},
+		"enabledInfo":{"status":false,"comment":{"text":"maintenance"},"statusSwitchTime":"20200102T030405+0000"},
+		"properties":{"property":[{"name":"env.JAVA_HOME","value":"/opt/jdk"},{"name":"system.agent.name","value":"linux-1"},{"name":"teamcity.agent.cpuBenchmark","value":"700"}]}}`, &requests))
+	defer done()
+
+	actual, err := client.Agents.GetByID(1)
+
+	require.NoError(t, err)
+	assert.True(t, actual.Connected)
+	assert.False(t, actual.Enabled)
+	assert.Equal(t, "Release", actual.Pool.Name)
+	assert.Equal(t, "maintenance", actual.EnabledInfo.Comment.Text)
+	assert.Equal(t, map[string]string{"JAVA_HOME": "/opt/jdk"}, actual.Environment())
+	assert.Equal(t, map[string]string{"agent.name": "linux-1"}, actual.SystemProperties())
+}
+
+func Test_AgentStatusChanges(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{}`, &requests))
+	defer done()
+
+	reenableAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, client.Agents.Authorize(1, "approved"))
+	require.NoError(t, client.Agents.Unauthorize(1, ""))
+	require.NoError(t, client.Agents.Disable(1, "maintenance", reenableAt))
+	require.NoError(t, client.Agents.Enable(1, ""))
+
+	require.Len(t, requests, 4)
+	assert.Equal(t, "PUT", requests[0].Method)
+	assert.Equal(t, "/httpAuth/app/rest/agents/id%3A1/authorizedInfo", requests[0].URI)
+	assert.JSONEq(t, `{"status":true,"comment":{"text":"approved"}}`, requests[0].Body)
+	assert.JSONEq(t, `{"status":false}`, requests[1].Body)
+	assert.Equal(t, "/httpAuth/app/rest/agents/id%3A1/enabledInfo", requests[2].URI)
+	assert.JSONEq(t, `{"status":false,"comment":{"text":"maintenance"},"statusSwitchTime":"20200102T030405+0000"}`, requests[2].Body)
+	assert.JSONEq(t, `{"status":true}`, requests[3].Body)
+}
+
+func Test_AgentMoveToDefaultPoolAndDelete(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"id":0,"name":"Default"}`, &requests))
+	defer done()
+
+	pool, err := client.Agents.MoveToPool(1, 0)
+	require.NoError(t, err)
+	require.NoError(t, client.Agents.Delete(1))
+
+	assert.Equal(t, "Default", pool.Name)
+	assert.Equal(t, "/httpAuth/app/rest/agents/id%3A1/pool", requests[0].URI)
+	assert.JSONEq(t, `{"id":0}`, requests[0].Body)
+	assert.Equal(t, "DELETE", requests[1].Method)
+	assert.Equal(t, "/httpAuth/app/rest/agents/id%3A1", requests[1].URI)
+}
@@ -0,0 +1,202 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+// ArtifactFile is a file, directory or archive entry published as an artifact of a build
+type ArtifactFile struct {
+	Name             string            `json:"name,omitempty" xml:"name"`
+	FullName         string            `json:"fullName,omitempty" xml:"fullName"`
+	Size             int64             `json:"size,omitempty" xml:"size"`
+	ModificationTime string            `json:"modificationTime,omitempty" xml:"modificationTime"`
+	Href             string            `json:"href,omitempty" xml:"href"`
+	Content          *artifactHref     `json:"content,omitempty"`
+	Children         *artifactChildren `json:"children,omitempty"`
+}
+
+type artifactHref struct {
+	Href string `json:"href,omitempty" xml:"href"`
+}
+
+type artifactChildren struct {
+	Href  string          `json:"href,omitempty" xml:"href"`
+	Count int             `json:"count,omitempty" xml:"count"`
+	Items []*ArtifactFile `json:"file,omitempty"`
+}
+
+// IsDir reports whether the artifact has children: a directory, or an archive when browsing archives
+func (f *ArtifactFile) IsDir() bool {
+	return f.Children != nil
+}
+
+// IsFile reports whether the artifact content can be downloaded. Archives are both files and directories.
+func (f *ArtifactFile) IsFile() bool {
+	return f.Content != nil
+}
+
+// ModifiedAt parses the modification time of the artifact
+func (f *ArtifactFile) ModifiedAt() (time.Time, error) {
+	return time.Parse(TimeFormat, f.ModificationTime)
+}
+
+type artifactFilesJSON struct {
+	Count int             `json:"count,omitempty" xml:"count"`
+	Items []*ArtifactFile `json:"file"`
+}
+
+// ListArtifactsOptions configures ArtifactService.List
+type ListArtifactsOptions struct {
+	// Recursive lists the whole tree below the path instead of its direct children only
+	Recursive bool
+
+	// BrowseArchives lists the content of zip, jar, tar.gz and other archives as if they were directories
+	BrowseArchives bool
+}
+
+// ArtifactService has operations for browsing and downloading build artifacts.
+// Paths are relative to the artifacts root of a build. Files inside archives are addressed with "!/",
+// as in "dist/package.zip!/bin/tool", and are extracted by the server.
+type ArtifactService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+	builds     *BuildService
+}
+
+func newArtifactService(base *sling.Sling, httpClient *http.Client, builds *BuildService) *ArtifactService {
+	sling := base.New().Path("builds/")
+	return &ArtifactService{
+		sling:      sling,
+		httpClient: httpClient,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+		builds:     builds,
+	}
+}
+
+// List returns the artifacts found at path for the build with given id. Use an empty path for the artifacts root. opts can be nil.
+func (s *ArtifactService) List(buildID int, path string, opts *ListArtifactsOptions) ([]*ArtifactFile, error) {
+	return s.ListWithContext(context.Background(), buildID, path, opts)
+}
+
+// ListWithContext returns the artifacts found at path for the build with given id, bound to ctx. opts can be nil.
+func (s *ArtifactService) ListWithContext(ctx context.Context, buildID int, path string, opts *ListArtifactsOptions) ([]*ArtifactFile, error) {
+	uri := artifactPath(buildID, "children", path)
+
+	b := NewLocatorBuilder()
+	if opts != nil && opts.Recursive {
+		b.Dimension("recursive", "true")
+	}
+	if opts != nil && opts.BrowseArchives {
+		b.Dimension("browseArchives", "true")
+	}
+	if len(b.Dimensions()) > 0 {
+		uri += "?locator=" + b.Locator().String()
+	}
+
+	var out artifactFilesJSON
+	err := s.restHelper.get(ctx, uri, &out, "build artifacts")
+	if err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// Download streams the content of the artifact file at path for the build with given id. The caller must close the returned reader.
+func (s *ArtifactService) Download(buildID int, path string) (io.ReadCloser, error) {
+	return s.DownloadWithContext(context.Background(), buildID, path)
+}
+
+// DownloadWithContext streams the content of the artifact file at path, bound to ctx. The caller must close the returned reader.
+func (s *ArtifactService) DownloadWithContext(ctx context.Context, buildID int, path string) (io.ReadCloser, error) {
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+	return s.stream(ctx, artifactPath(buildID, "content", path))
+}
+
+// DownloadArchive streams a zip archive with the artifacts below path matching pattern, for the build with given id.
+// Use an empty path and pattern to download all artifacts. Patterns use TeamCity's wildcards, such as "**/*.log".
+// The caller must close the returned reader.
+func (s *ArtifactService) DownloadArchive(buildID int, path string, pattern string) (io.ReadCloser, error) {
+	return s.DownloadArchiveWithContext(context.Background(), buildID, path, pattern)
+}
+
+// DownloadArchiveWithContext streams a zip archive with the artifacts below path matching pattern, bound to ctx.
+// The caller must close the returned reader.
+func (s *ArtifactService) DownloadArchiveWithContext(ctx context.Context, buildID int, path string, pattern string) (io.ReadCloser, error) {
+	uri := artifactPath(buildID, "archived", path)
+	if pattern != "" {
+		uri += "?locator=" + NewLocatorBuilder().Dimension("pattern", pattern).Locator().String()
+	}
+	return s.stream(ctx, uri)
+}
+
+// ResolveBuild finds the build of the given build configuration whose artifacts an artifact dependency with the given revision would use.
+// value is the build number for BuildWithSpecifiedNumber and the tag for LastBuildFinishedWithTag, and is ignored otherwise.
+// BuildFromSameChain has no chain to look at outside of a running build, so it resolves like LatestFinishedBuild.
+func (s *ArtifactService) ResolveBuild(buildTypeID string, revision ArtifactDependencyRevision, value string) (*Build, error) {
+	return s.ResolveBuildWithContext(context.Background(), buildTypeID, revision, value)
+}
+
+// ResolveBuildWithContext finds the build whose artifacts an artifact dependency with the given revision would use, bound to ctx.
+func (s *ArtifactService) ResolveBuildWithContext(ctx context.Context, buildTypeID string, revision ArtifactDependencyRevision, value string) (*Build, error) {
+	locator, err := artifactRevisionLocator(buildTypeID, revision, value)
+	if err != nil {
+		return nil, err
+	}
+	return s.builds.GetByLocatorWithContext(ctx, locator)
+}
+
+func artifactRevisionLocator(buildTypeID string, revision ArtifactDependencyRevision, value string) (Locator, error) {
+	if buildTypeID == "" {
+		return "", errors.New("buildTypeID is required")
+	}
+
+	b := NewLocatorBuilder().BuildType(NewLocatorBuilder().ID(buildTypeID))
+	switch revision {
+	case LatestSuccessfulBuild:
+		b.Status(BuildStatuses.Success)
+	case LatestPinnedBuild:
+		b.Dimension("pinned", "true")
+	case LatestFinishedBuild, BuildFromSameChain:
+	case BuildWithSpecifiedNumber, LastBuildFinishedWithTag:
+		if value == "" {
+			return "", fmt.Errorf("value is required for revision '%s'", revision)
+		}
+		if revision == BuildWithSpecifiedNumber {
+			b.Dimension("number", value)
+		} else {
+			b.Tag(value)
+		}
+	default:
+		return "", fmt.Errorf("unsupported revision '%s'", revision)
+	}
+	return b.State(BuildStates.Finished).Count(1).Locator(), nil
+}
+
+func (s *ArtifactService) stream(ctx context.Context, uri string) (io.ReadCloser, error) {
+	response, err := s.restHelper.getStream(ctx, s.sling.New().Get(uri).Set("Accept", "*/*"), "build artifact")
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// artifactPath builds "<build locator>/artifacts/<kind>/<path>", escaping every segment of path
+func artifactPath(buildID int, kind string, path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return fmt.Sprintf("%s/artifacts/%s/%s", LocatorIDInt(buildID), kind, strings.Join(segments, "/"))
+}
@@ -0,0 +1,129 @@
+package teamcity
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ArtifactsList(t *testing.T) {
+	var requested string
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count":2,"file":[
+			{"name":"bin","modificationTime":"20200102T030405+0000","children":{"href":"/app/rest/builds/id%3A1/artifacts/children/dist/bin"}},
+			{"name":"app.zip","size":1024,"modificationTime":"20200102T030405+0000","content":{"href":"/app/rest/builds/id%3A1/artifacts/content/dist/app.zip"},"children":{"href":"/app/rest/builds/id%3A1/artifacts/children/dist/app.zip"}}
+		]}`))
+	}))
+	defer done()
+
+	actual, err := client.Artifacts.List(1, "dist", &ListArtifactsOptions{BrowseArchives: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/httpAuth/app/rest/builds/id%3A1/artifacts/children/dist?locator=browseArchives%3Atrue", requested)
+	require.Len(t, actual, 2)
+	assert.True(t, actual[0].IsDir())
+	assert.False(t, actual[0].IsFile())
+	assert.True(t, actual[1].IsDir())
+	assert.True(t, actual[1].IsFile())
+	assert.Equal(t, int64(1024), actual[1].Size)
+	modified, err := actual[1].ModifiedAt()
+	require.NoError(t, err)
+	assert.Equal(t, 2020, modified.Year())
+}
+
+func Test_ArtifactsDownloadEscapesPath(t *testing.T) {
+	var requested string
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.EscapedPath()
+		w.Write([]byte("content"))
+	}))
+	defer done()
+
+	body, err := client.Artifacts.Download(1, "dist/my app.zip!/readme.txt")
+	require.NoError(t, err)
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, "/httpAuth/app/rest/builds/id%3A1/artifacts/content/dist/my%20app.zip%21/readme.txt", requested)
+}
+
+func Test_ArtifactsDownloadArchive(t *testing.T) {
+	var requested string
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		w.Write([]byte("PK"))
+	}))
+	defer done()
+
+	body, err := client.Artifacts.DownloadArchive(1, "", "**/*.log")
+	require.NoError(t, err)
+	body.Close()
+
+	assert.Equal(t, "/httpAuth/app/rest/builds/id%3A1/artifacts/archived/?locator=pattern%3A%2A%2A%2F%2A.log", requested)
+}
+
+func Test_ArtifactsDownloadArchiveEscapesPattern(t *testing.T) {
+	var pattern string
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern = r.URL.Query().Get("locator")
+		w.Write([]byte("PK"))
+	}))
+	defer done()
+
+	body, err := client.Artifacts.DownloadArchive(1, "", "+:dist/app (1),app (2).zip")
+	require.NoError(t, err)
+	body.Close()
+
+	assert.Equal(t, "pattern:(+:dist/app (1),app (2).zip)", pattern)
+	parsed, err := ParseLocator(pattern)
+	require.NoError(t, err)
+	value, _ := parsed.Lookup("pattern")
+	assert.Equal(t, "+:dist/app (1),app (2).zip", value)
+}
+
+func Test_ArtifactsDownloadNotFound(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "No artifact found", http.StatusNotFound)
+	}))
+	defer done()
+
+	_, err := client.Artifacts.Download(1, "missing.txt")
+
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+}
+
+func Test_ArtifactRevisionLocator(t *testing.T) {
+	cases := []struct {
+		revision ArtifactDependencyRevision
+		value    string
+		expected string
+	}{
+		{LatestSuccessfulBuild, "", "buildType:(id:Proj_Build),status:SUCCESS,state:finished,count:1"},
+		{LatestPinnedBuild, "", "buildType:(id:Proj_Build),pinned:true,state:finished,count:1"},
+		{LatestFinishedBuild, "", "buildType:(id:Proj_Build),state:finished,count:1"},
+		{BuildFromSameChain, "", "buildType:(id:Proj_Build),state:finished,count:1"},
+		{BuildWithSpecifiedNumber, "1.2.3", "buildType:(id:Proj_Build),number:1.2.3,state:finished,count:1"},
+		{LastBuildFinishedWithTag, "release", "buildType:(id:Proj_Build),tag:release,state:finished,count:1"},
+	}
+	for _, c := range cases {
+		locator, err := artifactRevisionLocator("Proj_Build", c.revision, c.value)
+		require.NoError(t, err)
+		actual, err := url.PathUnescape(locator.String())
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, actual, string(c.revision))
+	}
+
+	_, err := artifactRevisionLocator("Proj_Build", LastBuildFinishedWithTag, "")
+	assert.Error(t, err)
+	_, err = artifactRevisionLocator("Proj_Build", "unknown", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package teamcity
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Auth authenticates the requests sent by a Client. Implement it to plug in other authentication schemes,
+// such as the headers expected by an SSO proxy in front of TeamCity.
+type Auth interface {
+	// BasePath is the path of the REST API below the server address for this kind of authentication, such as "/httpAuth/app/rest/"
+	BasePath() string
+
+	// Decorate adds credentials to a request before it is sent. It is called again for every retry of a request.
+	Decorate(req *http.Request) error
+}
+
+// ResponseAuth is an Auth that also inspects responses, for example to keep the session cookie set by the server
+type ResponseAuth interface {
+	Auth
+
+	// HandleResponse is called with every response. Returning true sends the request once more, decorated anew,
+	// for example after a session expired and credentials have to be sent again.
+	HandleResponse(resp *http.Response) bool
+}
+
+const (
+	restPath          = "/app/rest/"
+	httpAuthRestPath  = "/httpAuth/app/rest/"
+	guestAuthRestPath = "/guestAuth/app/rest/"
+
+	// SessionCookieName is the cookie TeamCity keeps the session of an authenticated user in
+	SessionCookieName = "TCSESSIONID"
+)
+
+type basicAuth struct {
+	username, password string
+}
+
+// BasicAuth authenticates every request with the username and password
+func BasicAuth(username, password string) Auth {
+	return basicAuth{username, password}
+}
+
+func (a basicAuth) BasePath() string {
+	return httpAuthRestPath
+}
+
+func (a basicAuth) Decorate(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+type tokenAuth struct {
+	token string
+}
+
+// TokenAuth authenticates every request with an access token, see TokenService
+func TokenAuth(token string) Auth {
+	return tokenAuth{token}
+}
+
+func (a tokenAuth) BasePath() string {
+	return restPath
+}
+
+func (a tokenAuth) Decorate(req *http.Request) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.token))
+	return nil
+}
+
+type guestAuth struct{}
+
+// GuestAuth sends requests anonymously as the guest user. Guest access must be enabled on the server.
+func GuestAuth() Auth {
+	return guestAuth{}
+}
+
+func (a guestAuth) BasePath() string {
+	return guestAuthRestPath
+}
+
+func (a guestAuth) Decorate(req *http.Request) error {
+	return nil
+}
+
+type headerAuth struct {
+	headers map[string]string
+}
+
+// HeaderAuth sets the given headers on every request, for servers behind a proxy that authenticates users itself
+func HeaderAuth(headers map[string]string) Auth {
+	copied := make(map[string]string, len(headers))
+	for k, v := range headers {
+		copied[k] = v
+	}
+	return headerAuth{copied}
+}
+
+func (a headerAuth) BasePath() string {
+	return restPath
+}
+
+func (a headerAuth) Decorate(req *http.Request) error {
+	for k, v := range a.headers {
+		req.Header.Set(k, v)
+	}
+	return nil
+}
+
+type sessionAuth struct {
+	username, password string
+
+	mu      sync.Mutex
+	session *http.Cookie
+}
+
+// SessionAuth logs in with the username and password on the first request, then reuses the session cookie set by the server
+// instead of sending the credentials again. Credentials are only sent again when the session expires.
+func SessionAuth(username, password string) Auth {
+	return &sessionAuth{username: username, password: password}
+}
+
+func (a *sessionAuth) BasePath() string {
+	return httpAuthRestPath
+}
+
+func (a *sessionAuth) Decorate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session != nil {
+		req.AddCookie(a.session)
+		return nil
+	}
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+func (a *sessionAuth) HandleResponse(resp *http.Response) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The session expired: log in again, unless the credentials themselves were just rejected
+		expired := a.session != nil
+		a.session = nil
+		return expired
+	}
+	for _, c := range resp.Cookies() {
+		if c.Name == SessionCookieName && c.Value != "" {
+			a.session = &http.Cookie{Name: c.Name, Value: c.Value}
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package teamcity
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthTestServer records requests and answers with a project, unless reject returns true after writing its own response
+func newAuthTestServer(reject func(w http.ResponseWriter, r *http.Request) bool) (*httptest.Server, *[]*http.Request) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if reject != nil && reject(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"Project1","name":"Project 1"}`))
+	}))
+	return server, &requests
+}
+
+func Test_AuthRequired(t *testing.T) {
+	_, err := NewClientWithAddress(nil, "http://localhost:8111", http.DefaultClient)
+
+	assert.Error(t, err)
+}
+
+func Test_BasicAuthDecoratesRequests(t *testing.T) {
+	server, requests := newAuthTestServer(nil)
+	defer server.Close()
+	client, err := NewClientWithAddress(BasicAuth("admin", "secret"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/httpAuth/app/rest/projects/id:Project1", (*requests)[0].URL.Path)
+	username, password, ok := (*requests)[0].BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "admin", username)
+	assert.Equal(t, "secret", password)
+}
+
+func Test_TokenAuthDecoratesRequests(t *testing.T) {
+	server, requests := newAuthTestServer(nil)
+	defer server.Close()
+	client, err := NewClientWithAddress(TokenAuth("abc"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/app/rest/projects/id:Project1", (*requests)[0].URL.Path)
+	assert.Equal(t, "Bearer abc", (*requests)[0].Header.Get("Authorization"))
+}
+
+func Test_GuestAuthSendsNoCredentials(t *testing.T) {
+	server, requests := newAuthTestServer(nil)
+	defer server.Close()
+	client, err := NewClientWithAddress(GuestAuth(), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/guestAuth/app/rest/projects/id:Project1", (*requests)[0].URL.Path)
+	assert.Empty(t, (*requests)[0].Header.Get("Authorization"))
+}
+
+func Test_HeaderAuthSetsHeaders(t *testing.T) {
+	server, requests := newAuthTestServer(nil)
+	defer server.Close()
+	client, err := NewClientWithAddress(HeaderAuth(map[string]string{"X-Forwarded-User": "jdoe"}), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/app/rest/projects/id:Project1", (*requests)[0].URL.Path)
+	assert.Equal(t, "jdoe", (*requests)[0].Header.Get("X-Forwarded-User"))
+}
+
+func Test_SessionAuthReusesSessionCookie(t *testing.T) {
+	server, requests := newAuthTestServer(func(w http.ResponseWriter, r *http.Request) bool {
+		if _, _, ok := r.BasicAuth(); ok {
+			http.SetCookie(w, &http.Cookie{Name: SessionCookieName, Value: "s1"})
+		}
+		return false
+	})
+	defer server.Close()
+	client, err := NewClientWithAddress(SessionAuth("admin", "secret"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+	_, err = client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 2)
+	_, _, ok := (*requests)[0].BasicAuth()
+	assert.True(t, ok)
+	_, _, ok = (*requests)[1].BasicAuth()
+	assert.False(t, ok)
+	cookie, err := (*requests)[1].Cookie(SessionCookieName)
+	require.NoError(t, err)
+	assert.Equal(t, "s1", cookie.Value)
+}
+
+func Test_SessionAuthLogsInAgainWhenSessionExpires(t *testing.T) {
+	sessions := 0
+	server, requests := newAuthTestServer(func(w http.ResponseWriter, r *http.Request) bool {
+		if _, _, ok := r.BasicAuth(); ok {
+			sessions++
+			http.SetCookie(w, &http.Cookie{Name: SessionCookieName, Value: fmt.Sprintf("s%d", sessions)})
+			return false
+		}
+		if cookie, err := r.Cookie(SessionCookieName); err != nil || cookie.Value == "s1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return true
+		}
+		return false
+	})
+	defer server.Close()
+	client, err := NewClientWithAddress(SessionAuth("admin", "secret"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+	_, err = client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+	_, err = client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 4)
+	_, _, ok := (*requests)[2].BasicAuth()
+	assert.True(t, ok, "credentials are sent again after the session expired")
+	cookie, err := (*requests)[3].Cookie(SessionCookieName)
+	require.NoError(t, err)
+	assert.Equal(t, "s2", cookie.Value)
+}
+
+func Test_SessionAuthDoesNotResendRejectedCredentials(t *testing.T) {
+	server, requests := newAuthTestServer(func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusUnauthorized)
+		return true
+	})
+	defer server.Close()
+	client, err := NewClientWithAddress(SessionAuth("admin", "wrong"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+
+	_, err = client.Projects.GetByID("Project1")
+
+	require.Error(t, err)
+	assert.Len(t, *requests, 1)
+}
@@ -0,0 +1,389 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+// TimeFormat is the layout TeamCity uses for dates, both in resource representations and in locators
+const TimeFormat = "20060102T150405-0700"
+
+// BuildStates are the possible values of Build.State. Do not change the values.
+var BuildStates = struct {
+	Queued   string
+	Running  string
+	Finished string
+	Any      string
+}{
+	Queued:   "queued",
+	Running:  "running",
+	Finished: "finished",
+	Any:      "any",
+}
+
+// BuildStatuses are the possible values of Build.Status. Do not change the values.
+var BuildStatuses = struct {
+	Success string
+	Failure string
+	Error   string
+	Unknown string
+}{
+	Success: "SUCCESS",
+	Failure: "FAILURE",
+	Error:   "ERROR",
+	Unknown: "UNKNOWN",
+}
+
+// Build represents a queued, running or finished build
+type Build struct {
+	ID                 int                     `json:"id,omitempty" xml:"id"`
+	BuildTypeID        string                  `json:"buildTypeId,omitempty" xml:"buildTypeId"`
+	BuildType          *BuildTypeReference     `json:"buildType,omitempty"`
+	Number             string                  `json:"number,omitempty" xml:"number"`
+	State              string                  `json:"state,omitempty" xml:"state"`
+	Status             string                  `json:"status,omitempty" xml:"status"`
+	StatusText         string                  `json:"statusText,omitempty" xml:"statusText"`
+	BranchName         string                  `json:"branchName,omitempty" xml:"branchName"`
+	DefaultBranch      *bool                   `json:"defaultBranch,omitempty" xml:"defaultBranch"`
+	Personal           *bool                   `json:"personal,omitempty" xml:"personal"`
+	Pinned             *bool                   `json:"pinned,omitempty" xml:"pinned"`
+	PercentageComplete int                     `json:"percentageComplete,omitempty" xml:"percentageComplete"`
+	WaitReason         string                  `json:"waitReason,omitempty" xml:"waitReason"`
+	QueuedWaitReasons  *Properties             `json:"queuedWaitReasons,omitempty"`
+	QueuedDate         string                  `json:"queuedDate,omitempty" xml:"queuedDate"`
+	StartDate          string                  `json:"startDate,omitempty" xml:"startDate"`
+	FinishDate         string                  `json:"finishDate,omitempty" xml:"finishDate"`
+	Agent              *AgentReference         `json:"agent,omitempty"`
+	Comment            *BuildComment           `json:"comment,omitempty"`
+	Properties         *Parameters             `json:"properties,omitempty"`
+	Tags               *Tags                   `json:"tags,omitempty"`
+	TriggeringOptions  *BuildTriggeringOptions `json:"triggeringOptions,omitempty"`
+	Href               string                  `json:"href,omitempty" xml:"href"`
+	WebURL             string                  `json:"webUrl,omitempty" xml:"webUrl"`
+}
+
+// QueuedAt parses the date the build was added to the queue
+func (b *Build) QueuedAt() (time.Time, error) {
+	return time.Parse(TimeFormat, b.QueuedDate)
+}
+
+// StartedAt parses the date the build started running. Returns an error for builds still in the queue.
+func (b *Build) StartedAt() (time.Time, error) {
+	return time.Parse(TimeFormat, b.StartDate)
+}
+
+// FinishedAt parses the date the build finished. Returns an error for builds that haven't finished yet.
+func (b *Build) FinishedAt() (time.Time, error) {
+	return time.Parse(TimeFormat, b.FinishDate)
+}
+
+// Builds represents a collection of *Build
+type Builds struct {
+	Count    int      `json:"count,omitempty" xml:"count"`
+	Href     string   `json:"href,omitempty" xml:"href"`
+	NextHref string   `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*Build `json:"build"`
+}
+
+// BuildComment is the comment attached to a build when queuing or cancelling it
+type BuildComment struct {
+	Text      string `json:"text,omitempty" xml:"text"`
+	Timestamp string `json:"timestamp,omitempty" xml:"timestamp"`
+}
+
+// Tags is a collection of Tag
+type Tags struct {
+	Count int    `json:"count,omitempty" xml:"count"`
+	Items []*Tag `json:"tag"`
+}
+
+// Tag is a label attached to a build
+type Tag struct {
+	Name    string `json:"name,omitempty" xml:"name"`
+	Private *bool  `json:"private,omitempty" xml:"private"`
+}
+
+// BuildTriggeringOptions are the advanced options of the "Run custom build" dialog
+type BuildTriggeringOptions struct {
+	CleanSources                          *bool                `json:"cleanSources,omitempty" xml:"cleanSources"`
+	CleanSourcesInAllDependencies         *bool                `json:"cleanSourcesInAllDependencies,omitempty" xml:"cleanSourcesInAllDependencies"`
+	FreezeSettings                        *bool                `json:"freezeSettings,omitempty" xml:"freezeSettings"`
+	TagDependencies                       *bool                `json:"tagDependencies,omitempty" xml:"tagDependencies"`
+	QueueAtTop                            *bool                `json:"queueAtTop,omitempty" xml:"queueAtTop"`
+	RebuildAllDependencies                *bool                `json:"rebuildAllDependencies,omitempty" xml:"rebuildAllDependencies"`
+	RebuildFailedOrIncompleteDependencies *bool                `json:"rebuildFailedOrIncompleteDependencies,omitempty" xml:"rebuildFailedOrIncompleteDependencies"`
+	RebuildDependencies                   *BuildTypeReferences `json:"rebuildDependencies,omitempty"`
+}
+
+// QueueBuildOptions are the optional settings for queuing a build. The zero value queues the build on the default branch.
+type QueueBuildOptions struct {
+	// BranchName is the logical branch name to build
+	BranchName string
+
+	// Comment is attached to the queued build
+	Comment string
+
+	// Parameters overrides the build configuration parameters for this build only
+	Parameters *Parameters
+
+	// AgentID restricts the build to run on the given agent
+	AgentID *int
+
+	// Personal marks the build as personal
+	Personal bool
+
+	// TriggeringOptions configure clean checkout, queue position and how snapshot dependencies are rebuilt
+	TriggeringOptions *BuildTriggeringOptions
+}
+
+type buildCancelRequest struct {
+	Comment        string `json:"comment,omitempty" xml:"comment"`
+	ReaddIntoQueue bool   `json:"readdIntoQueue" xml:"readdIntoQueue"`
+}
+
+// BuildFilter restricts the builds returned by BuildService.List. Empty fields are not used for filtering.
+type BuildFilter struct {
+	// BuildTypeID lists only builds of the given build configuration
+	BuildTypeID string
+
+	// Branch is a branch name, or a branch locator such as "default:any". When empty, only default branch builds are returned.
+	Branch string
+
+	// Status is one of BuildStatuses
+	Status string
+
+	// State is one of BuildStates. When empty, only finished builds are returned.
+	State string
+
+	// Since lists only builds started after the given date
+	Since time.Time
+
+	// Tags lists only builds with all of the given tags
+	Tags []string
+
+	// Count limits the number of builds returned
+	Count int
+}
+
+func (f *BuildFilter) locator() Locator {
+	b := f.dimensions()
+	if f.Count > 0 {
+		b.Count(f.Count)
+	}
+	return b.Locator()
+}
+
+// dimensions returns the locator of the filter without its count, which iterators replace with the page size
+func (f *BuildFilter) dimensions() *LocatorBuilder {
+	b := NewLocatorBuilder()
+	if f.BuildTypeID != "" {
+		b.BuildType(NewLocatorBuilder().ID(f.BuildTypeID))
+	}
+	if f.Branch != "" {
+		b.Branch(branchLocator(f.Branch))
+	}
+	if f.Status != "" {
+		b.Status(f.Status)
+	}
+	if f.State != "" {
+		b.State(f.State)
+	}
+	if !f.Since.IsZero() {
+		b.Dimension("sinceDate", f.Since.Format(TimeFormat))
+	}
+	for _, t := range f.Tags {
+		b.Tag(t)
+	}
+	return b
+}
+
+// branchLocator reads branch as a branch locator when it is one, such as "default:any", and as a branch name otherwise
+func branchLocator(branch string) *LocatorBuilder {
+	if strings.Contains(branch, ":") {
+		if parsed, err := ParseLocator(branch); err == nil && namedDimensions(parsed) {
+			return parsed
+		}
+	}
+	return NewLocatorBuilder().Name(branch)
+}
+
+func namedDimensions(b *LocatorBuilder) bool {
+	for _, d := range b.dims {
+		if d.Name == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildService has operations for queuing, inspecting and cancelling builds
+type BuildService struct {
+	sling         *sling.Sling
+	httpClient    *http.Client
+	restHelper    *restHelper
+	queueHelper   *restHelper
+	problemHelper *restHelper
+}
+
+func newBuildService(base *sling.Sling, httpClient *http.Client) *BuildService {
+	sling := base.New().Path("builds/")
+	return &BuildService{
+		sling:         sling,
+		httpClient:    httpClient,
+		restHelper:    newRestHelperWithSling(httpClient, sling),
+		queueHelper:   newRestHelperWithSling(httpClient, base.New().Path("buildQueue/")),
+		problemHelper: newRestHelperWithSling(httpClient, base.New().Path("problemOccurrences/")),
+	}
+}
+
+// Queue adds a build of the build configuration with given id to the build queue. opts can be nil.
+func (s *BuildService) Queue(buildTypeID string, opts *QueueBuildOptions) (*Build, error) {
+	return s.QueueWithContext(context.Background(), buildTypeID, opts)
+}
+
+// QueueWithContext adds a build of the build configuration with given id to the build queue, bound to ctx. opts can be nil.
+func (s *BuildService) QueueWithContext(ctx context.Context, buildTypeID string, opts *QueueBuildOptions) (*Build, error) {
+	if buildTypeID == "" {
+		return nil, errors.New("buildTypeID is required")
+	}
+
+	request := &Build{
+		BuildType: &BuildTypeReference{ID: buildTypeID},
+	}
+	if opts != nil {
+		request.BranchName = opts.BranchName
+		request.Properties = opts.Parameters
+		request.TriggeringOptions = opts.TriggeringOptions
+		if opts.Comment != "" {
+			request.Comment = &BuildComment{Text: opts.Comment}
+		}
+		if opts.AgentID != nil {
+			request.Agent = &AgentReference{ID: *opts.AgentID}
+		}
+		if opts.Personal {
+			request.Personal = NewTrue()
+		}
+	}
+
+	var out Build
+	err := s.queueHelper.post(ctx, "", request, &out, "queue build")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// GetByID returns a queued, running or finished build by its id
+func (s *BuildService) GetByID(id int) (*Build, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext returns a queued, running or finished build by its id, bound to ctx
+func (s *BuildService) GetByIDWithContext(ctx context.Context, id int) (*Build, error) {
+	return s.GetByLocatorWithContext(ctx, LocatorIDInt(id))
+}
+
+// GetFields returns a queued, running or finished build by its id, with only the selected fields
+func (s *BuildService) GetFields(id int, fields Fields) (*Build, error) {
+	return s.GetFieldsWithContext(context.Background(), id, fields)
+}
+
+// GetFieldsWithContext returns a build by its id, with only the selected fields, bound to ctx
+func (s *BuildService) GetFieldsWithContext(ctx context.Context, id int, fields Fields) (*Build, error) {
+	var out Build
+	err := s.restHelper.get(ctx, LocatorIDInt(id).String()+fields.query(), &out, "build")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// GetByLocator returns the single build matching locator
+func (s *BuildService) GetByLocator(locator Locator) (*Build, error) {
+	return s.GetByLocatorWithContext(context.Background(), locator)
+}
+
+// GetByLocatorWithContext returns the single build matching locator, bound to ctx
+func (s *BuildService) GetByLocatorWithContext(ctx context.Context, locator Locator) (*Build, error) {
+	var out Build
+	err := s.restHelper.get(ctx, locator.String(), &out, "build")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// List returns the builds matching filter. filter can be nil, which lists the most recent finished builds on default branches.
+func (s *BuildService) List(filter *BuildFilter) ([]*Build, error) {
+	return s.ListWithContext(context.Background(), filter)
+}
+
+// ListWithContext returns the builds matching filter, bound to ctx. filter can be nil.
+func (s *BuildService) ListWithContext(ctx context.Context, filter *BuildFilter) ([]*Build, error) {
+	path := ""
+	if filter != nil {
+		if locator := filter.locator(); locator != "" {
+			path = "?locator=" + locator.String()
+		}
+	}
+
+	var out Builds
+	err := s.restHelper.get(ctx, path, &out, "builds")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate walks the builds matching filter, fetching pageSize builds per request. filter can be nil, which lists the finished builds
+// on default branches. filter.Count, when set, limits the number of builds visited. Use DefaultPageSize when pageSize is not positive.
+func (s *BuildService) Iterate(filter *BuildFilter, pageSize int) *BuildIterator {
+	return s.IterateWithContext(context.Background(), filter, pageSize)
+}
+
+// IterateWithContext walks the builds matching filter page by page, bound to ctx. filter can be nil.
+func (s *BuildService) IterateWithContext(ctx context.Context, filter *BuildFilter, pageSize int) *BuildIterator {
+	var locator Locator
+	limit := 0
+	if filter != nil {
+		locator, limit = filter.dimensions().Locator(), filter.Count
+	}
+	return newBuildIterator(ctx, s.restHelper, "?locator="+pagedLocator(locator, pageSize).String(), limit, "builds")
+}
+
+// Cancel stops a running build or removes a queued build from the queue. comment can be empty.
+func (s *BuildService) Cancel(id int, comment string) (*Build, error) {
+	return s.CancelWithContext(context.Background(), id, comment)
+}
+
+// CancelWithContext stops a running build or removes a queued build from the queue, bound to ctx. comment can be empty.
+func (s *BuildService) CancelWithContext(ctx context.Context, id int, comment string) (*Build, error) {
+	current, err := s.GetByIDWithContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Queued builds are cancelled through the queue, while running ones are stopped through the builds resource
+	helper := s.restHelper
+	if current.State == BuildStates.Queued {
+		helper = s.queueHelper
+	}
+
+	var out Build
+	request := &buildCancelRequest{Comment: comment}
+	err = helper.post(ctx, LocatorIDInt(id).String(), request, &out, "cancel build")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
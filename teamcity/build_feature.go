@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -58,6 +59,11 @@ func newBuildFeatureService(buildTypeID string, c *http.Client, base *sling.Slin
 
 //Create adds a new build feature to build type
 func (s *BuildFeatureService) Create(bf BuildFeature) (BuildFeature, error) {
+	return s.CreateWithContext(context.Background(), bf)
+}
+
+//CreateWithContext adds a new build feature to build type, bound to ctx
+func (s *BuildFeatureService) CreateWithContext(ctx context.Context, bf BuildFeature) (BuildFeature, error) {
 	if bf == nil {
 		return nil, errors.New("bf can't be nil")
 	}
@@ -67,7 +73,7 @@ func (s *BuildFeatureService) Create(bf BuildFeature) (BuildFeature, error) {
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +81,8 @@ func (s *BuildFeatureService) Create(bf BuildFeature) (BuildFeature, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Unknown error when adding build feature, statusCode: %d", resp.StatusCode)
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, newAPIError(resp, body, "build feature")
 	}
 
 	return s.readBuildFeatureResponse(resp)
@@ -83,13 +90,18 @@ func (s *BuildFeatureService) Create(bf BuildFeature) (BuildFeature, error) {
 
 //GetByID returns a build feature by its id
 func (s *BuildFeatureService) GetByID(id string) (BuildFeature, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+//GetByIDWithContext returns a build feature by its id, bound to ctx
+func (s *BuildFeatureService) GetByIDWithContext(ctx context.Context, id string) (BuildFeature, error) {
 	req, err := s.base.New().Get(id).Request()
 
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
 
 	if err != nil {
 		return nil, err
@@ -97,8 +109,9 @@ func (s *BuildFeatureService) GetByID(id string) (BuildFeature, error) {
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("404 Not Found - Build feature (id: %s) for buildTypeId (id: %s) was not found", id, s.BuildTypeID)
+	if resp.StatusCode != 200 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, newAPIError(resp, body, "build feature")
 	}
 
 	return s.readBuildFeatureResponse(resp)
@@ -106,8 +119,13 @@ func (s *BuildFeatureService) GetByID(id string) (BuildFeature, error) {
 
 //Delete removes a build feature from the build configuration by its id.
 func (s *BuildFeatureService) Delete(id string) error {
+	return s.DeleteWithContext(context.Background(), id)
+}
+
+//DeleteWithContext removes a build feature from the build configuration by its id, bound to ctx.
+func (s *BuildFeatureService) DeleteWithContext(ctx context.Context, id string) error {
 	request, _ := s.base.New().Delete(id).Request()
-	response, err := s.httpClient.Do(request)
+	response, err := s.httpClient.Do(request.WithContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -122,7 +140,7 @@ func (s *BuildFeatureService) Delete(id string) error {
 		if err != nil {
 			return err
 		}
-		return fmt.Errorf("Error '%d' when deleting build feature: %s", response.StatusCode, string(respData))
+		return newAPIError(response, respData, "build feature")
 	}
 
 	return nil
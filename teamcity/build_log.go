@@ -0,0 +1,160 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+//DefaultTailPollInterval is how often a log tail polls for new content when no PollInterval is given
+const DefaultTailPollInterval = 2 * time.Second
+
+// TailOptions configures BuildLogService.Tail
+type TailOptions struct {
+	// PollInterval is the delay between polls for new log content. Defaults to DefaultTailPollInterval.
+	PollInterval time.Duration
+
+	// Offset is the number of bytes of the log to skip, for resuming a tail
+	Offset int64
+}
+
+// BuildLogService has operations for downloading and following build logs.
+// Logs are streamed from the server and never loaded into memory as a whole.
+type BuildLogService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+	builds     *BuildService
+}
+
+func newBuildLogService(base *sling.Sling, httpClient *http.Client, builds *BuildService) *BuildLogService {
+	// The build log is served by the web UI next to the REST API, at "<auth prefix>/downloadBuildLog.html".
+	// Resolving it against the REST base keeps the authentication prefix and headers of the client.
+	sling := base.New().Path("../../").Set("Accept", "text/plain")
+	return &BuildLogService{
+		sling:      sling,
+		httpClient: httpClient,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+		builds:     builds,
+	}
+}
+
+// Download returns the full log of the build with given id. The caller must close the returned reader.
+func (s *BuildLogService) Download(buildID int) (io.ReadCloser, error) {
+	return s.DownloadWithContext(context.Background(), buildID)
+}
+
+// DownloadWithContext returns the full log of the build with given id, bound to ctx. The caller must close the returned reader.
+func (s *BuildLogService) DownloadWithContext(ctx context.Context, buildID int) (io.ReadCloser, error) {
+	return s.downloadFrom(ctx, buildID, 0)
+}
+
+// Tail follows the log of the build with given id, like "tail -f". The returned reader yields log content as the server
+// makes it available and reaches io.EOF once the build has finished and its whole log was read.
+// Reading fails with ctx's error when ctx is done. Closing the reader stops polling. opts can be nil.
+func (s *BuildLogService) Tail(ctx context.Context, buildID int, opts *TailOptions) io.ReadCloser {
+	if opts == nil {
+		opts = &TailOptions{}
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultTailPollInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(s.follow(ctx, buildID, opts.Offset, interval, pw))
+	}()
+	return &tailReader{PipeReader: pr, cancel: cancel, done: done}
+}
+
+// tailReader is the reader returned by Tail. Closing it cancels polling and waits for the poll in flight to end.
+type tailReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (r *tailReader) Close() error {
+	r.cancel()
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
+func (s *BuildLogService) follow(ctx context.Context, buildID int, offset int64, interval time.Duration, w io.Writer) error {
+	for {
+		// Check the state before fetching, so that the log read after a build is seen finished is complete
+		build, err := s.builds.GetByIDWithContext(ctx, buildID)
+		if err != nil {
+			return err
+		}
+
+		n, err := s.copyFrom(ctx, buildID, offset, w)
+		offset += n
+		if err != nil {
+			return err
+		}
+
+		if build.State == BuildStates.Finished {
+			return nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// copyFrom writes the log content past offset to w and returns the number of bytes written
+func (s *BuildLogService) copyFrom(ctx context.Context, buildID int, offset int64, w io.Writer) (int64, error) {
+	body, err := s.downloadFrom(ctx, buildID, offset)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// Nothing was appended to the log since the last poll
+			return 0, nil
+		}
+		// The log may not exist yet while the build is in the queue
+		if IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer body.Close()
+	return io.Copy(w, body)
+}
+
+func (s *BuildLogService) downloadFrom(ctx context.Context, buildID int, offset int64) (io.ReadCloser, error) {
+	req := s.sling.New().Get(fmt.Sprintf("downloadBuildLog.html?buildId=%d", buildID))
+	if offset > 0 {
+		req = req.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := s.restHelper.getStream(ctx, req, "build log")
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers that ignore the Range header send the whole log again
+	if offset > 0 && response.StatusCode != http.StatusPartialContent {
+		if _, err := io.CopyN(ioutil.Discard, response.Body, offset); err != nil && err != io.EOF {
+			response.Body.Close()
+			return nil, err
+		}
+	}
+	return response.Body, nil
+}
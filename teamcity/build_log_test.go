@@ -0,0 +1,123 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogServer grows the log of build 1 by one line on every poll, and finishes the build after the given number of lines
+type fakeLogServer struct {
+	mu            sync.Mutex
+	log           string
+	lines         int
+	ignoreRange   bool
+	requestedPath string
+	polls         int
+}
+
+func (f *fakeLogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasSuffix(r.URL.Path, "/downloadBuildLog.html") {
+		f.requestedPath = r.URL.Path
+		var offset int
+		if rng := r.Header.Get("Range"); rng != "" && !f.ignoreRange {
+			fmt.Sscanf(rng, "bytes=%d-", &offset)
+			if offset >= len(f.log) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		w.Write([]byte(f.log[offset:]))
+		return
+	}
+
+	f.polls++
+	state := BuildStates.Running
+	if f.lines == 0 {
+		state = BuildStates.Finished
+	} else {
+		f.log += fmt.Sprintf("line %d\n", f.lines)
+		f.lines--
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"id":1,"state":"%s"}`, state)
+}
+
+func Test_BuildLogDownload(t *testing.T) {
+	fake := &fakeLogServer{log: "full log\n"}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	log, err := client.BuildLogs.Download(1)
+	require.NoError(t, err)
+	defer log.Close()
+	actual, _ := ioutil.ReadAll(log)
+
+	assert.Equal(t, "full log\n", string(actual))
+	assert.Equal(t, "/httpAuth/downloadBuildLog.html", fake.requestedPath)
+}
+
+func Test_BuildLogTailFollowsUntilFinished(t *testing.T) {
+	for _, ignoreRange := range []bool{false, true} {
+		fake := &fakeLogServer{lines: 3, ignoreRange: ignoreRange}
+		client, done := newTestClient(t, fake)
+
+		tail := client.BuildLogs.Tail(context.Background(), 1, &TailOptions{PollInterval: time.Millisecond})
+		actual, err := ioutil.ReadAll(tail)
+		tail.Close()
+		done()
+
+		require.NoError(t, err)
+		assert.Equal(t, "line 3\nline 2\nline 1\n", string(actual))
+	}
+}
+
+func Test_BuildLogTailStopsOnContextCancellation(t *testing.T) {
+	fake := &fakeLogServer{lines: 1000}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	tail := client.BuildLogs.Tail(ctx, 1, &TailOptions{PollInterval: 5 * time.Millisecond})
+	defer tail.Close()
+	_, err := ioutil.ReadAll(tail)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+}
+
+func Test_BuildLogTailStopsPollingOnClose(t *testing.T) {
+	fake := &fakeLogServer{lines: 1000}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	tail := client.BuildLogs.Tail(context.Background(), 1, &TailOptions{PollInterval: time.Millisecond})
+	line := make([]byte, len("line 1000\n"))
+	_, err := io.ReadFull(tail, line)
+	require.NoError(t, err)
+	require.NoError(t, tail.Close())
+
+	fake.mu.Lock()
+	polls := fake.polls
+	fake.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, "line 1000\n", string(line))
+	assert.Equal(t, polls, fake.polls)
+}
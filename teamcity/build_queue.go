@@ -0,0 +1,249 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+// BuildQueueFilter restricts the builds returned by BuildQueueService.List. Empty fields are not used for filtering.
+type BuildQueueFilter struct {
+	// ProjectID lists only builds of build configurations in the given project
+	ProjectID string
+
+	// BuildTypeID lists only builds of the given build configuration
+	BuildTypeID string
+
+	// AgentPoolID lists only builds that can run on agents of the given pool. The default pool has id 0.
+	AgentPoolID *int
+
+	// Count limits the number of builds returned
+	Count int
+}
+
+func (f *BuildQueueFilter) locator() Locator {
+	b := f.dimensions()
+	if f.Count > 0 {
+		b.Count(f.Count)
+	}
+	return b.Locator()
+}
+
+// dimensions returns the locator of the filter without its count, which iterators replace with the page size
+func (f *BuildQueueFilter) dimensions() *LocatorBuilder {
+	b := NewLocatorBuilder()
+	if f.ProjectID != "" {
+		b.Project(NewLocatorBuilder().ID(f.ProjectID))
+	}
+	if f.BuildTypeID != "" {
+		b.BuildType(NewLocatorBuilder().ID(f.BuildTypeID))
+	}
+	if f.AgentPoolID != nil {
+		b.Pool(NewLocatorBuilder().ID(strconv.Itoa(*f.AgentPoolID)))
+	}
+	return b
+}
+
+// QueueWaitReason is one of the reasons a queued build spent time waiting, such as "Waiting for a compatible agent"
+type QueueWaitReason struct {
+	Reason string
+
+	// Duration is how long the build waited for this reason so far
+	Duration time.Duration
+}
+
+// QueueWaitInfo explains why a build is still in the queue
+type QueueWaitInfo struct {
+	Build *Build
+
+	// Reason is the current wait reason shown in the UI. Empty when the build is about to start.
+	Reason string
+
+	// Reasons are all the reasons the build waited for, with the time spent on each
+	Reasons []*QueueWaitReason
+
+	// CompatibleAgents are the agents able to run the build. An empty list means no connected and authorized agent meets its requirements.
+	CompatibleAgents []*AgentReference
+}
+
+const queuedBuildFields = "id,buildTypeId,state,branchName,defaultBranch,personal,waitReason,queuedDate,href,webUrl," +
+	"buildType(id,name,projectId,href),queuedWaitReasons(property(name,value))"
+
+// BuildQueueService has operations for inspecting and prioritising the build queue
+type BuildQueueService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+}
+
+func newBuildQueueService(base *sling.Sling, httpClient *http.Client) *BuildQueueService {
+	sling := base.New().Path("buildQueue/")
+	return &BuildQueueService{
+		sling:      sling,
+		httpClient: httpClient,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+	}
+}
+
+// List returns the queued builds matching filter, in queue order. filter can be nil, which lists the whole queue.
+func (s *BuildQueueService) List(filter *BuildQueueFilter) ([]*Build, error) {
+	return s.ListWithContext(context.Background(), filter)
+}
+
+// ListWithContext returns the queued builds matching filter in queue order, bound to ctx. filter can be nil.
+func (s *BuildQueueService) ListWithContext(ctx context.Context, filter *BuildQueueFilter) ([]*Build, error) {
+	path := "?fields=" + url.QueryEscape("count,build("+queuedBuildFields+")")
+	if filter != nil {
+		if locator := filter.locator(); locator != "" {
+			path += "&locator=" + locator.String()
+		}
+	}
+
+	var out Builds
+	err := s.restHelper.get(ctx, path, &out, "build queue")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate walks the queued builds matching filter in queue order, fetching pageSize builds per request. filter can be nil.
+// filter.Count, when set, limits the number of builds visited. Use DefaultPageSize when pageSize is not positive.
+func (s *BuildQueueService) Iterate(filter *BuildQueueFilter, pageSize int) *BuildIterator {
+	return s.IterateWithContext(context.Background(), filter, pageSize)
+}
+
+// IterateWithContext walks the queued builds matching filter page by page, bound to ctx. filter can be nil.
+func (s *BuildQueueService) IterateWithContext(ctx context.Context, filter *BuildQueueFilter, pageSize int) *BuildIterator {
+	var locator Locator
+	limit := 0
+	if filter != nil {
+		locator, limit = filter.dimensions().Locator(), filter.Count
+	}
+	path := "?fields=" + url.QueryEscape("count,nextHref,build("+queuedBuildFields+")") + "&locator=" + pagedLocator(locator, pageSize).String()
+	return newBuildIterator(ctx, s.restHelper, path, limit, "build queue")
+}
+
+// GetWaitInfo explains why the queued build with given id has not started yet
+func (s *BuildQueueService) GetWaitInfo(id int) (*QueueWaitInfo, error) {
+	return s.GetWaitInfoWithContext(context.Background(), id)
+}
+
+// GetWaitInfoWithContext explains why the queued build with given id has not started yet, bound to ctx
+func (s *BuildQueueService) GetWaitInfoWithContext(ctx context.Context, id int) (*QueueWaitInfo, error) {
+	var build Build
+	err := s.restHelper.get(ctx, LocatorIDInt(id).String()+"?fields="+url.QueryEscape(queuedBuildFields), &build, "queued build")
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := s.CompatibleAgentsWithContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &QueueWaitInfo{
+		Build:            &build,
+		Reason:           build.WaitReason,
+		CompatibleAgents: agents,
+	}
+	if build.QueuedWaitReasons != nil {
+		for _, p := range build.QueuedWaitReasons.Items {
+			reason := &QueueWaitReason{Reason: p.Name}
+			// TeamCity reports the time spent on each reason in milliseconds
+			if ms, err := strconv.ParseInt(p.Value, 10, 64); err == nil {
+				reason.Duration = time.Duration(ms) * time.Millisecond
+			}
+			out.Reasons = append(out.Reasons, reason)
+		}
+	}
+	return out, nil
+}
+
+// CompatibleAgents returns the agents that are able to run the queued build with given id
+func (s *BuildQueueService) CompatibleAgents(id int) ([]*AgentReference, error) {
+	return s.CompatibleAgentsWithContext(context.Background(), id)
+}
+
+// CompatibleAgentsWithContext returns the agents that are able to run the queued build with given id, bound to ctx
+func (s *BuildQueueService) CompatibleAgentsWithContext(ctx context.Context, id int) ([]*AgentReference, error) {
+	var out AgentReferences
+	err := s.restHelper.get(ctx, LocatorIDInt(id).String()+"/compatibleAgents", &out, "compatible agents")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// MoveToTop moves the queued build with given id to the top of the queue, so it starts on the next compatible agent that frees up
+func (s *BuildQueueService) MoveToTop(id int) error {
+	return s.MoveToTopWithContext(context.Background(), id)
+}
+
+// MoveToTopWithContext moves the queued build with given id to the top of the queue, bound to ctx
+func (s *BuildQueueService) MoveToTopWithContext(ctx context.Context, id int) error {
+	var out Build
+	return s.restHelper.put(ctx, "order/1", &Build{ID: id}, &out, "build queue position")
+}
+
+// Reorder moves the queued builds with given ids to the top of the queue, in the given order.
+// Builds not listed keep their relative order after them.
+func (s *BuildQueueService) Reorder(ids []int) error {
+	return s.ReorderWithContext(context.Background(), ids)
+}
+
+// ReorderWithContext moves the queued builds with given ids to the top of the queue in the given order, bound to ctx
+func (s *BuildQueueService) ReorderWithContext(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return errors.New("ids must not be empty")
+	}
+
+	request := &Builds{Count: len(ids)}
+	for _, id := range ids {
+		request.Items = append(request.Items, &Build{ID: id})
+	}
+
+	var out Builds
+	return s.restHelper.put(ctx, "order", request, &out, "build queue order")
+}
+
+// Remove removes the queued builds with given ids from the queue, attaching comment to each of them. comment can be empty.
+// It stops at the first build that cannot be removed; the builds before it are removed.
+func (s *BuildQueueService) Remove(ids []int, comment string) error {
+	return s.RemoveWithContext(context.Background(), ids, comment)
+}
+
+// RemoveWithContext removes the queued builds with given ids from the queue, bound to ctx. comment can be empty.
+func (s *BuildQueueService) RemoveWithContext(ctx context.Context, ids []int, comment string) error {
+	request := &buildCancelRequest{Comment: comment}
+	for _, id := range ids {
+		var out Build
+		err := s.restHelper.post(ctx, LocatorIDInt(id).String(), request, &out, "queued build")
+		if err != nil {
+			return fmt.Errorf("removing queued build %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// RemoveMatching removes every queued build matching filter in a single request.
+// filter must restrict the queue; use Remove with the ids from List to clear the whole queue.
+func (s *BuildQueueService) RemoveMatching(filter *BuildQueueFilter) error {
+	return s.RemoveMatchingWithContext(context.Background(), filter)
+}
+
+// RemoveMatchingWithContext removes every queued build matching filter in a single request, bound to ctx
+func (s *BuildQueueService) RemoveMatchingWithContext(ctx context.Context, filter *BuildQueueFilter) error {
+	if filter == nil || filter.locator() == "" {
+		return errors.New("filter must not be empty")
+	}
+	return s.restHelper.delete(ctx, "?locator="+filter.locator().String(), "build queue")
+}
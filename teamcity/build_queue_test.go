@@ -0,0 +1,126 @@
+package teamcity
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQueueServer struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	status   int
+}
+
+func (f *fakeQueueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, recordRequest(r))
+
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/httpAuth/app/rest/buildQueue/id:2/compatibleAgents":
+		w.Write([]byte(`{"count":1,"agent":[{"id":7,"name":"linux-1"}]}`))
+	case r.URL.Path == "/httpAuth/app/rest/buildQueue/id:2":
+		w.Write([]byte(`{"id":2,"state":"queued","waitReason":"Build is waiting for a compatible agent",
+			"queuedWaitReasons":{"property":[{"name":"Waiting for a compatible agent","value":"1500"}]}}`))
+	case r.Method == "GET":
+		w.Write([]byte(`{"count":2,"build":[{"id":1,"state":"queued"},{"id":2,"state":"queued"}]}`))
+	default:
+		w.Write([]byte(`{}`))
+	}
+}
+
+func Test_BuildQueueListFilters(t *testing.T) {
+	fake := &fakeQueueServer{}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	pool := 0
+	actual, err := client.BuildQueue.List(&BuildQueueFilter{ProjectID: "Release", AgentPoolID: &pool})
+
+	require.NoError(t, err)
+	assert.Len(t, actual, 2)
+	require.Len(t, fake.requests, 1)
+	assert.Contains(t, fake.requests[0].URI, "&locator=project%3A%28id%3ARelease%29%2Cpool%3A%28id%3A0%29")
+}
+
+func Test_BuildQueueGetWaitInfo(t *testing.T) {
+	fake := &fakeQueueServer{}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	actual, err := client.BuildQueue.GetWaitInfo(2)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Build is waiting for a compatible agent", actual.Reason)
+	require.Len(t, actual.Reasons, 1)
+	assert.Equal(t, "Waiting for a compatible agent", actual.Reasons[0].Reason)
+	assert.Equal(t, 1500*time.Millisecond, actual.Reasons[0].Duration)
+	require.Len(t, actual.CompatibleAgents, 1)
+	assert.Equal(t, "linux-1", actual.CompatibleAgents[0].Name)
+}
+
+func Test_BuildQueueMoveToTopAndReorder(t *testing.T) {
+	fake := &fakeQueueServer{}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	require.NoError(t, client.BuildQueue.MoveToTop(3))
+	require.NoError(t, client.BuildQueue.Reorder([]int{3, 1}))
+
+	require.Len(t, fake.requests, 2)
+	assert.Equal(t, "PUT", fake.requests[0].Method)
+	assert.Equal(t, "/httpAuth/app/rest/buildQueue/order/1", fake.requests[0].URI)
+	assert.JSONEq(t, `{"id":3}`, fake.requests[0].Body)
+	assert.Equal(t, "/httpAuth/app/rest/buildQueue/order", fake.requests[1].URI)
+	assert.JSONEq(t, `{"count":2,"build":[{"id":3},{"id":1}]}`, fake.requests[1].Body)
+}
+
+func Test_BuildQueueRemove(t *testing.T) {
+	fake := &fakeQueueServer{}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	require.NoError(t, client.BuildQueue.Remove([]int{1, 2}, "superseded by hotfix"))
+
+	require.Len(t, fake.requests, 2)
+	assert.Equal(t, "POST", fake.requests[1].Method)
+	assert.Equal(t, "/httpAuth/app/rest/buildQueue/id%3A2", fake.requests[1].URI)
+	assert.JSONEq(t, `{"comment":"superseded by hotfix","readdIntoQueue":false}`, fake.requests[1].Body)
+}
+
+func Test_BuildQueueRemoveReportsFailingBuild(t *testing.T) {
+	fake := &fakeQueueServer{status: http.StatusNotFound}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	err := client.BuildQueue.Remove([]int{5, 6}, "")
+
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "removing queued build 5")
+	assert.Len(t, fake.requests, 1)
+}
+
+func Test_BuildQueueRemoveMatchingRequiresFilter(t *testing.T) {
+	fake := &fakeQueueServer{}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	assert.Error(t, client.BuildQueue.RemoveMatching(nil))
+	assert.Error(t, client.BuildQueue.RemoveMatching(&BuildQueueFilter{}))
+	require.NoError(t, client.BuildQueue.RemoveMatching(&BuildQueueFilter{BuildTypeID: "Nightly"}))
+
+	require.Len(t, fake.requests, 1)
+	assert.Equal(t, "DELETE", fake.requests[0].Method)
+	assert.Equal(t, "/httpAuth/app/rest/buildQueue/?locator=buildType%3A%28id%3ANightly%29", fake.requests[0].URI)
+}
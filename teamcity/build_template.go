@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -27,11 +28,16 @@ func NewBuildTemplateService(buildTypeID string, c *http.Client, base *sling.Sli
 
 //Attach is an idempotent operation that attaches the build template with given ID to the build configuration fo this service.
 func (s *BuildTemplateService) Attach(buildTemplateID string) (*BuildTypeReference, error) {
+	return s.AttachWithContext(context.Background(), buildTemplateID)
+}
+
+//AttachWithContext attaches the build template with given ID to the build configuration fo this service, bound to ctx.
+func (s *BuildTemplateService) AttachWithContext(ctx context.Context, buildTemplateID string) (*BuildTypeReference, error) {
 	var out BuildTypeReference
 	dt := &BuildTypeReference{
 		ID: buildTemplateID,
 	}
-	err := s.restHelper.post("", dt, &out, "attach build template")
+	err := s.restHelper.post(ctx, "", dt, &out, "attach build template")
 
 	if err != nil {
 		return nil, err
@@ -42,5 +48,10 @@ func (s *BuildTemplateService) Attach(buildTemplateID string) (*BuildTypeReferen
 
 //Detach disassociates the build template with given ID from the build configuration fo this service.
 func (s *BuildTemplateService) Detach(buildTemplateID string) error {
-	return s.restHelper.delete(buildTemplateID, "detach build template")
+	return s.DetachWithContext(context.Background(), buildTemplateID)
+}
+
+//DetachWithContext disassociates the build template with given ID from the build configuration fo this service, bound to ctx.
+func (s *BuildTemplateService) DetachWithContext(ctx context.Context, buildTemplateID string) error {
+	return s.restHelper.delete(ctx, buildTemplateID, "detach build template")
 }
@@ -0,0 +1,78 @@
+package teamcity_test
+
+import (
+	"testing"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_QueueAndCancel(t *testing.T) {
+	client := setup()
+	assert := assert.New(t)
+	bt := createTestBuildType(t, client, testBuildTypeProjectId)
+	defer cleanUpProject(t, client, testBuildTypeProjectId)
+
+	params := teamcity.NewParametersEmpty()
+	params.AddOrReplaceValue(teamcity.ParameterTypes.Configuration, "param1", "value1")
+	opts := &teamcity.QueueBuildOptions{
+		Comment:    "queued by integration tests",
+		Parameters: params,
+		TriggeringOptions: &teamcity.BuildTriggeringOptions{
+			QueueAtTop:             teamcity.NewTrue(),
+			RebuildAllDependencies: teamcity.NewTrue(),
+		},
+	}
+
+	queued, err := client.Builds.Queue(bt.ID, opts)
+	require.NoError(t, err)
+	assert.NotZero(queued.ID)
+	assert.Equal(bt.ID, queued.BuildTypeID)
+	assert.Equal(teamcity.BuildStates.Queued, queued.State)
+
+	actual, err := client.Builds.GetByID(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(queued.ID, actual.ID)
+	assert.Equal("queued by integration tests", actual.Comment.Text)
+
+	cancelled, err := client.Builds.Cancel(queued.ID, "cancelled by integration tests")
+	require.NoError(t, err)
+	assert.Equal(teamcity.BuildStates.Finished, cancelled.State)
+}
+
+func TestBuild_List(t *testing.T) {
+	client := setup()
+	bt := createTestBuildType(t, client, testBuildTypeProjectId)
+	defer cleanUpProject(t, client, testBuildTypeProjectId)
+
+	queued, err := client.Builds.Queue(bt.ID, nil)
+	require.NoError(t, err)
+	client.Builds.Cancel(queued.ID, "")
+
+	actual, err := client.Builds.List(&teamcity.BuildFilter{
+		BuildTypeID: bt.ID,
+		State:       teamcity.BuildStates.Any,
+		Branch:      "default:any",
+	})
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, queued.ID, actual[0].ID)
+}
+
+func TestBuild_QueueRequiresBuildType(t *testing.T) {
+	client := setup()
+
+	_, err := client.Builds.Queue("", nil)
+
+	require.EqualError(t, err, "buildTypeID is required")
+}
+
+func TestBuild_GetNotFound(t *testing.T) {
+	client := setup()
+
+	_, err := client.Builds.GetByID(999999)
+
+	require.Error(t, err)
+	assert.True(t, teamcity.IsNotFound(err))
+}
@@ -1,10 +1,11 @@
 package teamcity
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"github.com/dghubble/sling"
 )
@@ -58,6 +59,9 @@ type BuildType struct {
 	Steps       []Step
 	Templates   *Templates
 
+	// Paused is only returned by the server. Paused build configurations are not triggered automatically.
+	Paused bool
+
 	VcsRootEntries []*VcsRootEntry
 	Parameters     *Parameters
 	buildTypeJSON  *buildTypeJSON
@@ -142,23 +146,50 @@ func (b *BuildType) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// partialBuildType reads a build type from a partial response, where settings are only present when requested.
+// Unlike BuildType, Options are left nil rather than defaulted when the response has no settings.
+type partialBuildType struct {
+	BuildType
+}
+
+func (b *partialBuildType) UnmarshalJSON(data []byte) error {
+	var aux buildTypeJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if err := b.read(&aux); err != nil {
+		return err
+	}
+	if aux.Settings == nil {
+		b.Options = nil
+	}
+	return nil
+}
+
 func (b *BuildType) read(dt *buildTypeJSON) error {
 	var isTemplate bool
 	if dt.TemplateFlag != nil {
 		isTemplate = *dt.TemplateFlag
 		b.IsTemplate = isTemplate
 	}
-	opts := dt.Settings.buildTypeOptions(isTemplate)
-
 	b.ID = dt.ID
 	b.Name = dt.Name
 	b.Description = dt.Description
-	b.Options = opts
 	b.ProjectID = dt.ProjectID
-	b.VcsRootEntries = dt.VcsRootEntries.Items
 	b.Parameters = dt.Parameters
 	b.Templates = dt.Templates
+	b.Paused = dt.Paused != nil && *dt.Paused
 
+	b.Options = dt.Settings.buildTypeOptions(isTemplate)
+
+	// Collections are missing from partial responses
+	if dt.VcsRootEntries != nil {
+		b.VcsRootEntries = dt.VcsRootEntries.Items
+	}
+	if dt.Steps == nil {
+		b.Steps = nil
+		return nil
+	}
 	steps := make([]Step, dt.Steps.Count)
 	for i := range dt.Steps.Items {
 		dt, err := json.Marshal(dt.Steps.Items[i])
@@ -198,6 +229,9 @@ type BuildTypeReferences struct {
 	// count
 	Count int32 `json:"count,omitempty" xml:"count"`
 
+	// NextHref is the address of the next page, when listing page by page
+	NextHref string `json:"nextHref,omitempty" xml:"nextHref"`
+
 	// buildType
 	Items []*BuildTypeReference `json:"buildType"`
 }
@@ -230,10 +264,16 @@ func newBuildTypeService(base *sling.Sling, httpClient *http.Client) *BuildTypeS
 // Create creates a new build type under a project
 // NOTE: the "projectID" field is unused - set the ProjectID field on `buildType` instead
 func (s *BuildTypeService) Create(projectID string, buildType *BuildType) (*BuildTypeReference, error) {
+	return s.CreateWithContext(context.Background(), projectID, buildType)
+}
+
+// CreateWithContext creates a new build type under a project, bound to ctx
+// NOTE: the "projectID" field is unused - set the ProjectID field on `buildType` instead
+func (s *BuildTypeService) CreateWithContext(ctx context.Context, projectID string, buildType *BuildType) (*BuildTypeReference, error) {
 	// TODO: remove the unused 'projectID' parameter above in a major release
 	var created BuildTypeReference
 
-	err := s.restHelper.post("", buildType, &created, "Build Type")
+	err := s.restHelper.post(ctx, "", buildType, &created, "Build Type")
 
 	if err != nil {
 		return nil, err
@@ -244,48 +284,229 @@ func (s *BuildTypeService) Create(projectID string, buildType *BuildType) (*Buil
 
 // GetByID Retrieves a build type resource by ID
 func (s *BuildTypeService) GetByID(id string) (*BuildType, error) {
-	var out BuildType
+	return s.GetByIDWithContext(context.Background(), id)
+}
 
-	resp, err := s.sling.New().Get(id).ReceiveSuccess(&out)
+// GetByIDWithContext Retrieves a build type resource by ID, bound to ctx
+func (s *BuildTypeService) GetByIDWithContext(ctx context.Context, id string) (*BuildType, error) {
+	var out BuildType
 
+	err := s.restHelper.get(ctx, id, &out, "build type")
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Error when retrieving BuildType id = '%s', status: %d", id, resp.StatusCode)
-	}
-
 	//For now, filter all inherited parameters, until figuring out a proper way of exposing filtering options to the caller
 	out.Parameters = out.Parameters.NonInherited()
 
 	return &out, err
 }
 
+// GetFields retrieves a build type resource by ID, with only the selected fields
+func (s *BuildTypeService) GetFields(id string, fields Fields) (*BuildType, error) {
+	return s.GetFieldsWithContext(context.Background(), id, fields)
+}
+
+// GetFieldsWithContext retrieves a build type resource by ID, with only the selected fields, bound to ctx
+func (s *BuildTypeService) GetFieldsWithContext(ctx context.Context, id string, fields Fields) (*BuildType, error) {
+	var out partialBuildType
+
+	err := s.restHelper.get(ctx, id+fields.query(), &out, "build type")
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Parameters != nil {
+		out.Parameters = out.Parameters.NonInherited()
+	}
+	return &out.BuildType, nil
+}
+
+// ListReferences returns references to all build configurations and templates, fetching only the fields of BuildTypeReference
+func (s *BuildTypeService) ListReferences() ([]*BuildTypeReference, error) {
+	return s.ListReferencesWithContext(context.Background())
+}
+
+// ListReferencesWithContext returns references to all build configurations and templates, bound to ctx
+func (s *BuildTypeService) ListReferencesWithContext(ctx context.Context) ([]*BuildTypeReference, error) {
+	return s.ListWithContext(ctx, nil)
+}
+
+// BuildTypeFilter restricts the build configurations and templates returned by BuildTypeService.List.
+// Nil and empty fields are not used for filtering.
+type BuildTypeFilter struct {
+	// ProjectID lists only build types of the given project
+	ProjectID string
+
+	// Recursive includes the build types of all subprojects of ProjectID, not only its direct build types
+	Recursive bool
+
+	// TemplateFlag lists only templates when true, or only build configurations when false. Both are listed when nil.
+	TemplateFlag *bool
+
+	// Paused lists only paused build configurations when true, or only active ones when false
+	Paused *bool
+
+	// VcsRootID lists only build types attached to the given VCS root
+	VcsRootID string
+
+	// TemplateID lists only build configurations based on the given template
+	TemplateID string
+
+	// ParameterName lists only build types defining the given parameter, with the value ParameterValue if not empty
+	ParameterName  string
+	ParameterValue string
+}
+
+func (f *BuildTypeFilter) locator() Locator {
+	if f == nil {
+		f = &BuildTypeFilter{}
+	}
+	b := NewLocatorBuilder()
+	if f.ProjectID != "" {
+		if f.Recursive {
+			b.AffectedProject(NewLocatorBuilder().ID(f.ProjectID))
+		} else {
+			b.Project(NewLocatorBuilder().ID(f.ProjectID))
+		}
+	}
+	// Without this, the server lists build configurations only
+	if f.TemplateFlag != nil {
+		b.Dimension("templateFlag", fmt.Sprintf("%t", *f.TemplateFlag))
+	} else {
+		b.Dimension("templateFlag", "any")
+	}
+	if f.Paused != nil {
+		b.Dimension("paused", fmt.Sprintf("%t", *f.Paused))
+	}
+	if f.VcsRootID != "" {
+		b.Sub("vcsRoot", NewLocatorBuilder().ID(f.VcsRootID))
+	}
+	if f.TemplateID != "" {
+		b.Sub("template", NewLocatorBuilder().ID(f.TemplateID))
+	}
+	if f.ParameterName != "" {
+		parameter := NewLocatorBuilder().Name(f.ParameterName)
+		if f.ParameterValue != "" {
+			parameter.Dimension("value", f.ParameterValue).Dimension("matchType", "equals")
+		}
+		b.Sub("parameter", parameter)
+	}
+	return b.Locator()
+}
+
+// List returns references to the build configurations and templates matching filter, which can be nil to list all of them
+func (s *BuildTypeService) List(filter *BuildTypeFilter) ([]*BuildTypeReference, error) {
+	return s.ListWithContext(context.Background(), filter)
+}
+
+// ListWithContext returns references to the build configurations and templates matching filter, bound to ctx
+func (s *BuildTypeService) ListWithContext(ctx context.Context, filter *BuildTypeFilter) ([]*BuildTypeReference, error) {
+	var out BuildTypeReferences
+
+	fields := NewFields("count").Nested("buildType", BuildTypeReferenceFields)
+	err := s.restHelper.get(ctx, "?locator="+filter.locator().String()+"&fields="+url.QueryEscape(fields.String()), &out, "build types")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate walks references to the build configurations and templates matching filter, fetching pageSize build types per request.
+// filter can be nil. Use DefaultPageSize when pageSize is not positive.
+func (s *BuildTypeService) Iterate(filter *BuildTypeFilter, pageSize int) *BuildTypeIterator {
+	return s.IterateWithContext(context.Background(), filter, pageSize)
+}
+
+// IterateWithContext walks references to the build configurations and templates matching filter page by page, bound to ctx
+func (s *BuildTypeService) IterateWithContext(ctx context.Context, filter *BuildTypeFilter, pageSize int) *BuildTypeIterator {
+	fields := NewFields("count", "nextHref").Nested("buildType", BuildTypeReferenceFields)
+	return newBuildTypeIterator(ctx, s.restHelper, "?locator="+pagedLocator(filter.locator(), pageSize).String()+"&fields="+url.QueryEscape(fields.String()))
+}
+
+// ListFields returns the build configurations and templates matching filter with only the selected fields, in a single request
+func (s *BuildTypeService) ListFields(filter *BuildTypeFilter, fields Fields) ([]*BuildType, error) {
+	return s.ListFieldsWithContext(context.Background(), filter, fields)
+}
+
+// ListFieldsWithContext returns the build configurations and templates matching filter with only the selected fields, bound to ctx
+func (s *BuildTypeService) ListFieldsWithContext(ctx context.Context, filter *BuildTypeFilter, fields Fields) ([]*BuildType, error) {
+	var out struct {
+		Count int                 `json:"count,omitempty"`
+		Items []*partialBuildType `json:"buildType"`
+	}
+
+	err := s.restHelper.get(ctx, "?locator="+filter.locator().String()+"&fields="+url.QueryEscape(NewFields("count").Nested("buildType", fields).String()), &out, "build types")
+	if err != nil {
+		return nil, err
+	}
+
+	buildTypes := make([]*BuildType, len(out.Items))
+	for i, b := range out.Items {
+		if b.Parameters != nil {
+			b.Parameters = b.Parameters.NonInherited()
+		}
+		buildTypes[i] = &b.BuildType
+	}
+	return buildTypes, nil
+}
+
+// ListFull returns the full representations of the build configurations and templates matching filter, as GetByID does.
+// It sends one request per build type: use ListFields to read only some fields of many build types.
+func (s *BuildTypeService) ListFull(filter *BuildTypeFilter) ([]*BuildType, error) {
+	return s.ListFullWithContext(context.Background(), filter)
+}
+
+// ListFullWithContext returns the full representations of the build configurations and templates matching filter, bound to ctx
+func (s *BuildTypeService) ListFullWithContext(ctx context.Context, filter *BuildTypeFilter) ([]*BuildType, error) {
+	refs, err := s.ListWithContext(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*BuildType, 0, len(refs))
+	for _, ref := range refs {
+		buildType, err := s.GetByIDWithContext(ctx, ref.ID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, buildType)
+	}
+	return out, nil
+}
+
 //Update changes the resource in-place for this build configuration.
 //TeamCity API does not support "PUT" on the whole Build Configuration resource, so the only updateable fields are "Name" and "Description". Other field updates will be ignored.
 //This method also updates Settings and Parameters, but this is not an atomic operation. If an error occurs, it will be returned to caller what was updated or not.
 func (s *BuildTypeService) Update(buildType *BuildType) (*BuildType, error) {
-	_, err := s.restHelper.putTextPlain(buildType.ID+"/name", buildType.Name, "build type name")
+	return s.UpdateWithContext(context.Background(), buildType)
+}
+
+//UpdateWithContext changes the resource in-place for this build configuration, bound to ctx. See Update for the caveats.
+func (s *BuildTypeService) UpdateWithContext(ctx context.Context, buildType *BuildType) (*BuildType, error) {
+	_, err := s.restHelper.putTextPlain(ctx, buildType.ID+"/name", buildType.Name, "build type name")
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = s.restHelper.putTextPlain(buildType.ID+"/description", buildType.Description, "build type description")
+	_, err = s.restHelper.putTextPlain(ctx, buildType.ID+"/description", buildType.Description, "build type description")
 	if err != nil {
 		return nil, err
 	}
 
-	//Update settings
-	var settings BuildTypeOptions
-	err = s.restHelper.put(buildType.ID+"/settings", buildType.Options.properties(), &settings, "build type settings")
-	if err != nil {
-		return nil, err
+	//Update settings, unless they were not read
+	if buildType.Options != nil {
+		var settings BuildTypeOptions
+		err = s.restHelper.put(ctx, buildType.ID+"/settings", buildType.Options.properties(), &settings, "build type settings")
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	//Update Parameters
 	var parameters *Properties
-	err = s.restHelper.put(buildType.ID+"/parameters", buildType.Parameters, &parameters, "build type parameters")
+	err = s.restHelper.put(ctx, buildType.ID+"/parameters", buildType.Parameters, &parameters, "build type parameters")
 	if err != nil {
 		return nil, err
 	}
@@ -293,13 +514,13 @@ func (s *BuildTypeService) Update(buildType *BuildType) (*BuildType, error) {
 	//Update Steps
 	if buildType.Steps != nil && len(buildType.Steps) > 0 {
 		var steps []Step
-		err = s.restHelper.putCustom(buildType.ID+"/steps", buildType.serializeSteps(), &steps, "build type steps", stepsReadingFunc)
+		err = s.restHelper.putCustom(ctx, buildType.ID+"/steps", buildType.serializeSteps(), &steps, "build type steps", stepsReadingFunc)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	out, err := s.GetByID(buildType.ID) //Refresh after update
+	out, err := s.GetByIDWithContext(ctx, buildType.ID) //Refresh after update
 	if err != nil {
 		return nil, err
 	}
@@ -309,38 +530,34 @@ func (s *BuildTypeService) Update(buildType *BuildType) (*BuildType, error) {
 
 //Delete a build type resource
 func (s *BuildTypeService) Delete(id string) error {
-	request, _ := s.sling.New().Delete(id).Request()
-	response, err := s.httpClient.Do(request)
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-	if response.StatusCode == 204 {
-		return nil
-	}
-
-	if response.StatusCode != 200 && response.StatusCode != 204 {
-		respData, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Error '%d' when deleting build type: %s", response.StatusCode, string(respData))
-	}
+	return s.DeleteWithContext(context.Background(), id)
+}
 
-	return nil
+//DeleteWithContext deletes a build type resource, bound to ctx
+func (s *BuildTypeService) DeleteWithContext(ctx context.Context, id string) error {
+	return s.restHelper.delete(ctx, id, "build type")
 }
 
 // AttachVcsRoot adds the VcsRoot reference to this build type
 func (s *BuildTypeService) AttachVcsRoot(id string, vcsRoot *VcsRootReference) error {
+	return s.AttachVcsRootWithContext(context.Background(), id, vcsRoot)
+}
+
+// AttachVcsRootWithContext adds the VcsRoot reference to this build type, bound to ctx
+func (s *BuildTypeService) AttachVcsRootWithContext(ctx context.Context, id string, vcsRoot *VcsRootReference) error {
 	var vcsEntry = NewVcsRootEntry(vcsRoot)
-	return s.AttachVcsRootEntry(id, vcsEntry)
+	return s.AttachVcsRootEntryWithContext(ctx, id, vcsEntry)
 }
 
 // AttachVcsRootEntry adds the VcsRootEntry to this build type
 func (s *BuildTypeService) AttachVcsRootEntry(id string, entry *VcsRootEntry) error {
+	return s.AttachVcsRootEntryWithContext(context.Background(), id, entry)
+}
+
+// AttachVcsRootEntryWithContext adds the VcsRootEntry to this build type, bound to ctx
+func (s *BuildTypeService) AttachVcsRootEntryWithContext(ctx context.Context, id string, entry *VcsRootEntry) error {
 	var created VcsRootEntry
-	_, err := s.sling.New().Post(fmt.Sprintf("%s/vcs-root-entries/", LocatorID(id))).BodyJSON(entry).ReceiveSuccess(&created)
+	err := s.restHelper.post(ctx, fmt.Sprintf("%s/vcs-root-entries/", LocatorID(id)), entry, &created, "vcs root entry")
 
 	if err != nil {
 		return err
@@ -351,10 +568,15 @@ func (s *BuildTypeService) AttachVcsRootEntry(id string, entry *VcsRootEntry) er
 
 // AddStep creates a new build step for the build configuration with given id.
 func (s *BuildTypeService) AddStep(id string, step Step) (Step, error) {
+	return s.AddStepWithContext(context.Background(), id, step)
+}
+
+// AddStepWithContext creates a new build step for the build configuration with given id, bound to ctx.
+func (s *BuildTypeService) AddStepWithContext(ctx context.Context, id string, step Step) (Step, error) {
 	var created Step
 	path := fmt.Sprintf("%s/steps/", LocatorID(id))
 
-	err := s.restHelper.postCustom(path, step, &created, "build step", stepReadingFunc)
+	err := s.restHelper.postCustom(ctx, path, step, &created, "build step", stepReadingFunc)
 	if err != nil {
 		return nil, err
 	}
@@ -364,9 +586,14 @@ func (s *BuildTypeService) AddStep(id string, step Step) (Step, error) {
 
 //GetSteps return the list of steps for a Build configuration with given id.
 func (s *BuildTypeService) GetSteps(id string) ([]Step, error) {
+	return s.GetStepsWithContext(context.Background(), id)
+}
+
+//GetStepsWithContext return the list of steps for a Build configuration with given id, bound to ctx.
+func (s *BuildTypeService) GetStepsWithContext(ctx context.Context, id string) ([]Step, error) {
 	var aux stepsJSON
 	path := fmt.Sprintf("%s/steps/", LocatorID(id))
-	err := s.restHelper.get(path, &aux, "build steps")
+	err := s.restHelper.get(ctx, path, &aux, "build steps")
 	if err != nil {
 		return nil, err
 	}
@@ -385,13 +612,15 @@ func (s *BuildTypeService) GetSteps(id string) ([]Step, error) {
 // UpdateSettings will do a remote call for each setting being updated. Operation is not atomic, and the list of settings is processed in the order sent.
 // Will return the error of the first failure and not process the rest
 func (s *BuildTypeService) UpdateSettings(id string, settings *Properties) error {
+	return s.UpdateSettingsWithContext(context.Background(), id, settings)
+}
+
+// UpdateSettingsWithContext will do a remote call for each setting being updated, bound to ctx. See UpdateSettings for the caveats.
+func (s *BuildTypeService) UpdateSettingsWithContext(ctx context.Context, id string, settings *Properties) error {
 	for _, item := range settings.Items {
-		bodyProvider := textPlainBodyProvider{payload: item.Value}
-		req, err := s.sling.New().Put(fmt.Sprintf("%s/settings/%s", LocatorID(id), item.Name)).BodyProvider(bodyProvider).Add("Accept", "text/plain").Request()
-		response, err := s.httpClient.Do(req)
-		response.Body.Close()
+		_, err := s.restHelper.putTextPlain(ctx, fmt.Sprintf("%s/settings/%s", LocatorID(id), item.Name), item.Value, "build type setting")
 		if err != nil {
-			return fmt.Errorf("error updating buildType id: '%s' setting '%s': %s", id, item.Name, err)
+			return fmt.Errorf("error updating buildType id: '%s' setting '%s': %w", id, item.Name, err)
 		}
 	}
 
@@ -400,11 +629,10 @@ func (s *BuildTypeService) UpdateSettings(id string, settings *Properties) error
 
 //DeleteStep removes a build step from this build type by its id
 func (s *BuildTypeService) DeleteStep(id string, stepID string) error {
-	_, err := s.sling.New().Delete(fmt.Sprintf("%s/steps/%s", LocatorID(id), stepID)).ReceiveSuccess(nil)
-
-	if err != nil {
-		return err
-	}
+	return s.DeleteStepWithContext(context.Background(), id, stepID)
+}
 
-	return nil
+//DeleteStepWithContext removes a build step from this build type by its id, bound to ctx
+func (s *BuildTypeService) DeleteStepWithContext(ctx context.Context, id string, stepID string) error {
+	return s.restHelper.delete(ctx, fmt.Sprintf("%s/steps/%s", LocatorID(id), stepID), "build step")
 }
@@ -0,0 +1,80 @@
+package teamcity
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBuildTypesClient answers list requests with body and others with a full build type, recording the locators requested
+func newBuildTypesClient(t *testing.T, body string, locators *[]string) (*Client, func()) {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*locators = append(*locators, r.URL.Query().Get("locator"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("locator") == "" {
+			fmt.Fprintf(w, `{"id":"%s","name":"Full","projectId":"A","steps":{"count":0},"parameters":{"count":0}}`, r.URL.Path[len("/httpAuth/app/rest/buildTypes/"):])
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+}
+
+func Test_BuildTypeFilterLocator(t *testing.T) {
+	templates, paused := true, false
+	sut := &BuildTypeFilter{
+		ProjectID:      "Parent",
+		Recursive:      true,
+		TemplateFlag:   &templates,
+		Paused:         &paused,
+		VcsRootID:      "Parent_Repo",
+		TemplateID:     "Parent_Base",
+		ParameterName:  "env",
+		ParameterValue: "prod, eu",
+	}
+	unescaped, err := url.QueryUnescape(sut.locator().String())
+	require.NoError(t, err)
+	actual, err := ParseLocator(unescaped)
+	require.NoError(t, err)
+
+	assert.Equal(t, "affectedProject:(id:Parent),templateFlag:true,paused:false,vcsRoot:(id:Parent_Repo),template:(id:Parent_Base),parameter:(name:env,value:(prod, eu),matchType:equals)", actual.String())
+}
+
+func Test_BuildTypeFilterLocatorDefaults(t *testing.T) {
+	assert.Equal(t, "templateFlag%3Aany", (*BuildTypeFilter)(nil).locator().String())
+	assert.Equal(t, "project%3A%28id%3AParent%29%2CtemplateFlag%3Aany%2Cparameter%3A%28name%3Aenv%29", (&BuildTypeFilter{ProjectID: "Parent", ParameterName: "env"}).locator().String())
+}
+
+func Test_BuildTypeList(t *testing.T) {
+	var locators []string
+	client, done := newBuildTypesClient(t, `{"count":2,"buildType":[{"id":"A_Build","name":"Build","projectId":"A"},{"id":"A_Test","name":"Test","projectId":"A"}]}`, &locators)
+	defer done()
+
+	refs, err := client.BuildTypes.List(&BuildTypeFilter{ProjectID: "A"})
+	require.NoError(t, err)
+	assert.Len(t, refs, 2)
+
+	full, err := client.BuildTypes.ListFull(&BuildTypeFilter{ProjectID: "A"})
+	require.NoError(t, err)
+	require.Len(t, full, 2)
+	assert.Equal(t, "A_Test", full[1].ID)
+	assert.Equal(t, "Full", full[1].Name)
+	assert.Equal(t, []string{"project:(id:A),templateFlag:any", "project:(id:A),templateFlag:any", "", ""}, locators)
+}
+
+func Test_BuildTypeListFields(t *testing.T) {
+	var locators []string
+	client, done := newBuildTypesClient(t, `{"count":1,"buildType":[{"id":"A_Build","paused":true,"parameters":{"count":2,"property":[{"name":"env","value":"prod"},{"name":"inherited","value":"x","inherited":true}]}}]}`, &locators)
+	defer done()
+
+	actual, err := client.BuildTypes.ListFields(nil, NewFields("id", "paused").Nested("parameters", NewFields("$long")))
+
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "A_Build", actual[0].ID)
+	assert.True(t, actual[0].Paused)
+	assert.EqualValues(t, 1, actual[0].Parameters.Count)
+}
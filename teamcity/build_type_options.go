@@ -52,6 +52,9 @@ func NewBuildTypeOptionsTemplate() *BuildTypeOptions {
 }
 
 func (o *BuildTypeOptions) properties() *Properties {
+	if o == nil {
+		return nil
+	}
 	props := serializeToProperties(o)
 
 	//TeamCity API for build settings has a very weird behavior to omit some properties when they assume their "default" value.
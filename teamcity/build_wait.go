@@ -0,0 +1,177 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	//DefaultWaitPollInterval is how often WaitForBuild polls the build when no PollInterval is given
+	DefaultWaitPollInterval = 5 * time.Second
+
+	// cancelOnTimeoutDeadline bounds the cancellation request sent after the caller's context expired
+	cancelOnTimeoutDeadline = 30 * time.Second
+)
+
+// WaitForBuildOptions configures how BuildService.WaitForBuild polls a build
+type WaitForBuildOptions struct {
+	// PollInterval is the delay between the first polls. Defaults to DefaultWaitPollInterval.
+	PollInterval time.Duration
+
+	// BackoffFactor multiplies the delay after every poll, so long builds are polled less often. Values below 1 disable backoff.
+	BackoffFactor float64
+
+	// MaxPollInterval caps the delay between polls when BackoffFactor is used
+	MaxPollInterval time.Duration
+
+	// OnProgress is called on the first poll and whenever the state or percentage complete of the build changes
+	OnProgress func(BuildProgress)
+
+	// CancelOnTimeout cancels the build when ctx reaches its deadline before the build finishes
+	CancelOnTimeout bool
+}
+
+// BuildProgress is reported to WaitForBuildOptions.OnProgress
+type BuildProgress struct {
+	// Build is the latest representation of the build
+	Build *Build
+
+	// PreviousState is the state observed on the previous poll, empty on the first one
+	PreviousState string
+
+	// State is the current state, one of BuildStates
+	State string
+
+	// PercentageComplete is the progress estimated by TeamCity for running builds
+	PercentageComplete int
+}
+
+// BuildResult is the outcome of a finished build
+type BuildResult struct {
+	Build      *Build
+	Status     string
+	StatusText string
+	Problems   []*ProblemOccurrence
+}
+
+// Successful reports whether the build finished with BuildStatuses.Success
+func (r *BuildResult) Successful() bool {
+	return r.Status == BuildStatuses.Success
+}
+
+// ProblemOccurrence is a problem reported by a build, such as a failed step, an exit code or a compilation error
+type ProblemOccurrence struct {
+	ID         string `json:"id,omitempty" xml:"id"`
+	Type       string `json:"type,omitempty" xml:"type"`
+	Identity   string `json:"identity,omitempty" xml:"identity"`
+	Details    string `json:"details,omitempty" xml:"details"`
+	Additional string `json:"additionalData,omitempty" xml:"additionalData"`
+	Href       string `json:"href,omitempty" xml:"href"`
+}
+
+type problemOccurrencesJSON struct {
+	Count int                  `json:"count,omitempty" xml:"count"`
+	Items []*ProblemOccurrence `json:"problemOccurrence"`
+}
+
+// WaitForBuild polls the build with given id until it finishes, then returns its status and problems.
+// Polling stops with ctx's error when ctx is done; set CancelOnTimeout to also cancel the build in that case. opts can be nil.
+func (s *BuildService) WaitForBuild(ctx context.Context, id int, opts *WaitForBuildOptions) (*BuildResult, error) {
+	if opts == nil {
+		opts = &WaitForBuildOptions{}
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultWaitPollInterval
+	}
+
+	var last *Build
+	for {
+		build, err := s.GetByIDWithContext(ctx, id)
+		if err != nil {
+			return nil, s.waitFailed(ctx, id, opts, err)
+		}
+
+		if opts.OnProgress != nil && (last == nil || last.State != build.State || last.PercentageComplete != build.PercentageComplete) {
+			progress := BuildProgress{
+				Build:              build,
+				State:              build.State,
+				PercentageComplete: build.PercentageComplete,
+			}
+			if last != nil {
+				progress.PreviousState = last.State
+			}
+			opts.OnProgress(progress)
+		}
+		last = build
+
+		if build.State == BuildStates.Finished {
+			return s.buildResult(ctx, build)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, s.waitFailed(ctx, id, opts, ctx.Err())
+		case <-timer.C:
+		}
+
+		if opts.BackoffFactor > 1 {
+			interval = time.Duration(float64(interval) * opts.BackoffFactor)
+			if opts.MaxPollInterval > 0 && interval > opts.MaxPollInterval {
+				interval = opts.MaxPollInterval
+			}
+		}
+	}
+}
+
+// GetProblems returns the problems reported by the build with given id
+func (s *BuildService) GetProblems(id int) ([]*ProblemOccurrence, error) {
+	return s.GetProblemsWithContext(context.Background(), id)
+}
+
+// GetProblemsWithContext returns the problems reported by the build with given id, bound to ctx
+func (s *BuildService) GetProblemsWithContext(ctx context.Context, id int) ([]*ProblemOccurrence, error) {
+	var out problemOccurrencesJSON
+	locator := NewLocatorBuilder().Sub("build", NewLocatorBuilder().ID(strconv.Itoa(id))).Locator()
+	err := s.problemHelper.get(ctx, "?fields=problemOccurrence(id,type,identity,details,additionalData,href)&locator="+locator.String(), &out, "build problems")
+	if err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+func (s *BuildService) buildResult(ctx context.Context, build *Build) (*BuildResult, error) {
+	result := &BuildResult{
+		Build:      build,
+		Status:     build.Status,
+		StatusText: build.StatusText,
+	}
+	if build.Status == BuildStatuses.Success {
+		return result, nil
+	}
+
+	problems, err := s.GetProblemsWithContext(ctx, build.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Problems = problems
+	return result, nil
+}
+
+func (s *BuildService) waitFailed(ctx context.Context, id int, opts *WaitForBuildOptions, err error) error {
+	if !opts.CancelOnTimeout || ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+
+	// ctx is already expired, so the cancellation gets a deadline of its own
+	cancelCtx, cancel := context.WithTimeout(context.Background(), cancelOnTimeoutDeadline)
+	defer cancel()
+	if _, cancelErr := s.CancelWithContext(cancelCtx, id, "Cancelled after timing out waiting for the build to finish"); cancelErr != nil {
+		return fmt.Errorf("%w (cancelling build %d also failed: %s)", err, id, cancelErr)
+	}
+	return err
+}
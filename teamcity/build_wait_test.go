@@ -0,0 +1,102 @@
+package teamcity
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBuildServer struct {
+	mu        sync.Mutex
+	states    []string
+	polls     int
+	cancelled string
+}
+
+func (f *fakeBuildServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == "POST":
+		f.cancelled = r.URL.Path
+		w.Write([]byte(`{"id":1,"state":"finished","status":"UNKNOWN"}`))
+	case strings.Contains(r.URL.Path, "problemOccurrences"):
+		w.Write([]byte(`{"count":1,"problemOccurrence":[{"id":"problem:(id:1)","type":"TC_EXIT_CODE","details":"Process exited with code 1"}]}`))
+	default:
+		state := f.states[f.polls]
+		if f.polls < len(f.states)-1 {
+			f.polls++
+		}
+		w.Write([]byte(state))
+	}
+}
+
+func Test_WaitForBuildReportsProgressAndProblems(t *testing.T) {
+	fake := &fakeBuildServer{states: []string{
+		`{"id":1,"state":"queued"}`,
+		`{"id":1,"state":"queued"}`,
+		`{"id":1,"state":"running","percentageComplete":50}`,
+		`{"id":1,"state":"finished","status":"FAILURE","statusText":"Exit code 1"}`,
+	}}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	var progress []BuildProgress
+	opts := &WaitForBuildOptions{
+		PollInterval: time.Millisecond,
+		OnProgress: func(p BuildProgress) {
+			progress = append(progress, p)
+		},
+	}
+	actual, err := client.Builds.WaitForBuild(context.Background(), 1, opts)
+
+	require.NoError(t, err)
+	assert.False(t, actual.Successful())
+	assert.Equal(t, BuildStatuses.Failure, actual.Status)
+	assert.Equal(t, "Exit code 1", actual.StatusText)
+	require.Len(t, actual.Problems, 1)
+	assert.Equal(t, "TC_EXIT_CODE", actual.Problems[0].Type)
+
+	require.Len(t, progress, 3)
+	assert.Equal(t, "", progress[0].PreviousState)
+	assert.Equal(t, BuildStates.Queued, progress[0].State)
+	assert.Equal(t, BuildStates.Queued, progress[1].PreviousState)
+	assert.Equal(t, BuildStates.Running, progress[1].State)
+	assert.Equal(t, 50, progress[1].PercentageComplete)
+	assert.Equal(t, BuildStates.Finished, progress[2].State)
+}
+
+func Test_WaitForBuildCancelsOnTimeout(t *testing.T) {
+	fake := &fakeBuildServer{states: []string{`{"id":1,"state":"queued"}`}}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Builds.WaitForBuild(ctx, 1, &WaitForBuildOptions{PollInterval: time.Millisecond, CancelOnTimeout: true})
+
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), context.DeadlineExceeded.Error()))
+	assert.Equal(t, "/httpAuth/app/rest/buildQueue/id:1", fake.cancelled)
+}
+
+func Test_WaitForBuildDoesNotCancelByDefault(t *testing.T) {
+	fake := &fakeBuildServer{states: []string{`{"id":1,"state":"running"}`}}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Builds.WaitForBuild(ctx, 1, &WaitForBuildOptions{PollInterval: time.Millisecond})
+
+	require.Error(t, err)
+	assert.Equal(t, "", fake.cancelled)
+}
@@ -0,0 +1,165 @@
+package teamcity
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var errBodyNotRewindable = errors.New("request body cannot be sent again")
+
+// clientTransport is the http.RoundTripper behind every request a Client sends, whether issued by restHelper or by sling.
+// It applies the client-wide request policies and then delegates to Client.HTTPClient, so timeouts, redirects,
+// cookies and transports configured by the caller are kept.
+type clientTransport struct {
+	client *Client
+}
+
+func newRestClient(c *Client) *http.Client {
+	return &http.Client{
+		Transport: &clientTransport{client: c},
+		// Redirects are already followed, or deliberately not, by Client.HTTPClient
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.doWithRetry(t.withRequestID(req))
+}
+
+func (t *clientTransport) doWithRetry(req *http.Request) (*http.Response, error) {
+	policy := t.client.retryPolicy()
+	if policy == nil {
+		return t.send(req)
+	}
+
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := t.send(req)
+
+		wait, retry := policy.Retry(req, attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if timeout := t.client.RetryTimeout; timeout > 0 && time.Since(started)+wait > timeout {
+			return resp, err
+		}
+		next, rewindErr := rewindRequest(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain so the connection can be reused for the next attempt
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+// send performs a single attempt through the caller's http client. State-changing requests carry the cached CSRF token;
+// when the server rejects the token, or the lack of one, a new token is fetched and the request sent once more.
+func (t *clientTransport) send(req *http.Request) (*http.Response, error) {
+	if !needsCSRF(req.Method) || t.client.auth == nil {
+		return t.sendAuthenticated(req)
+	}
+
+	token := t.client.csrf.get()
+	resp, err := t.sendWithCSRF(req, token)
+	if err != nil || !isCSRFFailure(resp) {
+		return resp, err
+	}
+	next, rewindErr := rewindRequest(req)
+	if rewindErr != nil {
+		return resp, nil
+	}
+	fresh, csrfErr := t.refreshCSRFToken(req.Context(), token)
+	if csrfErr != nil || fresh == "" {
+		// Report the rejection itself rather than the failure to recover from it
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	return t.sendWithCSRF(next, fresh)
+}
+
+func (t *clientTransport) sendWithCSRF(req *http.Request, token string) (*http.Response, error) {
+	if token != "" {
+		// A RoundTripper must not modify the request it was given
+		req = req.Clone(req.Context())
+		req.Header.Set(CSRFHeader, token)
+	}
+	return t.sendAuthenticated(req)
+}
+
+// sendAuthenticated sends req authenticated with Client.auth. When the authentication asks for it,
+// for example after a session expired, the request is sent once more.
+func (t *clientTransport) sendAuthenticated(req *http.Request) (*http.Response, error) {
+	resp, err := t.sendDecorated(req)
+	if err != nil {
+		return resp, err
+	}
+
+	handler, ok := t.client.auth.(ResponseAuth)
+	if !ok || !handler.HandleResponse(resp) {
+		return resp, nil
+	}
+	next, rewindErr := rewindRequest(req)
+	if rewindErr != nil {
+		return resp, nil
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	resp, err = t.sendDecorated(next)
+	if err == nil {
+		handler.HandleResponse(resp)
+	}
+	return resp, err
+}
+
+func (t *clientTransport) sendDecorated(req *http.Request) (*http.Response, error) {
+	if auth := t.client.auth; auth != nil {
+		req = req.Clone(req.Context())
+		if err := auth.Decorate(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.doLogged(req)
+	if uerr, ok := err.(*url.Error); ok {
+		// The outer http.Client wraps the error with method and URL again
+		err = uerr.Err
+	}
+	return resp, err
+}
+
+// rewindRequest returns a copy of req that can be sent again, with a fresh body
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, errBodyNotRewindable
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
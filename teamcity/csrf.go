@@ -0,0 +1,132 @@
+package teamcity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CSRFHeader is the header TeamCity expects the CSRF token in on state-changing requests
+const CSRFHeader = "X-TC-CSRF-Token"
+
+// csrfCache holds the CSRF token of a client. It is fetched the first time the server rejects a request without it.
+type csrfCache struct {
+	mu    sync.Mutex
+	token string
+
+	// fetch is the token fetch in flight, shared by the requests rejected meanwhile. It is nil when no fetch is in flight.
+	fetch *csrfFetch
+}
+
+// csrfFetch is a token fetch. done is closed once token and err are set.
+type csrfFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func (c *csrfCache) get() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// needsCSRF reports whether TeamCity checks the CSRF token for a request with given method
+func needsCSRF(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// refreshCSRFToken fetches a new CSRF token from the server, replacing stale, and caches it.
+// When another request already replaced stale, the token it fetched is returned instead. Concurrent refreshes share one fetch,
+// which runs without holding the cache lock.
+func (t *clientTransport) refreshCSRFToken(ctx context.Context, stale string) (string, error) {
+	cache := &t.client.csrf
+	for {
+		cache.mu.Lock()
+		if cache.token != stale {
+			token := cache.token
+			cache.mu.Unlock()
+			return token, nil
+		}
+		fetch := cache.fetch
+		if fetch == nil {
+			fetch = &csrfFetch{done: make(chan struct{})}
+			cache.fetch = fetch
+			cache.mu.Unlock()
+
+			fetch.token, fetch.err = t.fetchCSRFToken(ctx)
+			cache.mu.Lock()
+			if fetch.err == nil {
+				cache.token = fetch.token
+			}
+			cache.fetch = nil
+			cache.mu.Unlock()
+			close(fetch.done)
+			return fetch.token, fetch.err
+		}
+		cache.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-fetch.done:
+		}
+		// A fetch cancelled by the context of another request is tried again with this one
+		if fetch.err != nil && (errors.Is(fetch.err, context.Canceled) || errors.Is(fetch.err, context.DeadlineExceeded)) {
+			continue
+		}
+		return fetch.token, fetch.err
+	}
+}
+
+// fetchCSRFToken requests a CSRF token from the server
+func (t *clientTransport) fetchCSRFToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, t.csrfURL(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := t.sendAuthenticated(req.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("fetching CSRF token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("fetching CSRF token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching CSRF token: unexpected status %d", resp.StatusCode)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// csrfURL is the address of the CSRF token endpoint. It sits at the server root, under the authentication prefix
+// of the REST API path, such as "/httpAuth", whatever the API version or the rest of the path.
+func (t *clientTransport) csrfURL() string {
+	prefix := ""
+	if i := strings.Index(t.client.auth.BasePath(), restPath); i >= 0 {
+		prefix = t.client.auth.BasePath()[:i]
+	}
+	return strings.TrimSuffix(t.client.address, "/") + prefix + "/authenticationTest.html?csrf"
+}
+
+// isCSRFFailure reports whether the server rejected a request because of a missing or stale CSRF token.
+// The body of resp is read and replaced, so that it can still be read by the caller.
+func isCSRFFailure(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return bytes.Contains(body, []byte("CSRF"))
+}
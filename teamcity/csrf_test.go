@@ -0,0 +1,181 @@
+package teamcity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCSRFServer struct {
+	// path of the CSRF token endpoint, "/httpAuth/authenticationTest.html" when empty
+	path     string
+	token    string
+	fetches  int
+	requests []recordedRequest
+}
+
+func (s *fakeCSRFServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := s.path
+	if path == "" {
+		path = "/httpAuth/authenticationTest.html"
+	}
+	if r.URL.Path == path {
+		s.fetches++
+		w.Write([]byte(s.token))
+		return
+	}
+
+	s.requests = append(s.requests, recordRequest(r))
+	if r.Method != http.MethodGet && r.Header.Get(CSRFHeader) != s.token {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Responding with 403 status code due to failed CSRF check: no \"Origin\" header is present and no authentication provided with the request, consider adding \"Origin: http://teamcity\" header."))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"name":"ci-bot","value":"eyJ0eXAi"}`))
+}
+
+func Test_CSRFTokenFetchedOnRejectionAndCached(t *testing.T) {
+	fake := &fakeCSRFServer{token: "csrf-1"}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	token, _ := NewToken("ci-bot", time.Time{})
+	_, err := client.Tokens.Create("", token)
+	require.NoError(t, err)
+	err = client.Tokens.Delete("", "ci-bot")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fake.fetches)
+	require.Len(t, fake.requests, 3)
+	assert.Equal(t, fake.requests[0].Body, fake.requests[1].Body, "the rejected request is sent again with its body")
+	assert.Equal(t, "DELETE", fake.requests[2].Method)
+}
+
+// basePathAuth authenticates with basic auth, with the REST API at a custom path
+type basePathAuth struct {
+	basicAuth
+	path string
+}
+
+func (a basePathAuth) BasePath() string {
+	return a.path
+}
+
+func Test_CSRFTokenFetchedFromServerRootWithCustomBasePath(t *testing.T) {
+	cases := []struct {
+		basePath, csrfPath string
+	}{
+		{"/httpAuth/app/rest/2018.1/", "/httpAuth/authenticationTest.html"},
+		{"/app/rest/latest/", "/authenticationTest.html"},
+		{"/proxy/api/", "/authenticationTest.html"},
+	}
+	for _, c := range cases {
+		fake := &fakeCSRFServer{path: c.csrfPath, token: "csrf-1"}
+		server := httptest.NewServer(fake)
+		client, err := NewClientWithAddress(basePathAuth{basicAuth{"admin", "admin"}, c.basePath}, server.URL, http.DefaultClient)
+		require.NoError(t, err)
+
+		err = client.Tokens.Delete("", "ci-bot")
+		server.Close()
+
+		require.NoError(t, err, c.basePath)
+		assert.Equal(t, 1, fake.fetches, c.basePath)
+	}
+}
+
+func Test_CSRFTokenRefreshedWhenStale(t *testing.T) {
+	fake := &fakeCSRFServer{token: "csrf-1"}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	err := client.Tokens.Delete("", "ci-bot")
+	require.NoError(t, err)
+	fake.token = "csrf-2"
+	err = client.Tokens.Delete("", "ci-bot")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, fake.fetches)
+	assert.Len(t, fake.requests, 4)
+}
+
+func Test_CSRFTokenNotFetchedForReads(t *testing.T) {
+	fake := &fakeCSRFServer{token: "csrf-1"}
+	client, done := newTestClient(t, fake)
+	defer done()
+
+	_, err := client.Tokens.List("")
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, fake.fetches)
+}
+
+func Test_CSRFRejectionReportedWhenTokenUnavailable(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("CSRF Header X-TC-CSRF-Token does not match CSRF session value"))
+	}))
+	defer done()
+
+	err := client.Tokens.Delete("", "ci-bot")
+
+	require.Error(t, err)
+	assert.True(t, IsForbidden(err))
+	assert.Contains(t, err.Error(), "CSRF")
+}
+
+func Test_CSRFTokenFetchedOnceForConcurrentRejections(t *testing.T) {
+	var mu sync.Mutex
+	fetches := 0
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/httpAuth/authenticationTest.html" {
+			mu.Lock()
+			fetches++
+			mu.Unlock()
+			close(fetching)
+			<-release
+			w.Write([]byte("csrf-1"))
+			return
+		}
+		if r.Header.Get(CSRFHeader) != "csrf-1" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("CSRF Header X-TC-CSRF-Token does not match CSRF session value"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer done()
+
+	const requests = 5
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			errs <- client.Tokens.Delete("", "ci-bot")
+		}()
+	}
+
+	<-fetching
+	cached := make(chan string)
+	go func() {
+		cached <- client.csrf.get()
+	}()
+	select {
+	case token := <-cached:
+		assert.Equal(t, "", token)
+	case <-time.After(time.Second):
+		t.Fatal("the token cache is locked while the token is fetched")
+	}
+	close(release)
+
+	for i := 0; i < requests; i++ {
+		require.NoError(t, <-errs)
+	}
+	assert.Equal(t, 1, fetches)
+}
@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -35,53 +36,57 @@ func NewDependencyService(buildTypeID string, c *http.Client, base *sling.Sling)
 
 //AddSnapshotDependency adds a new snapshot dependency to build type
 func (s *DependencyService) AddSnapshotDependency(dep *SnapshotDependency) (*SnapshotDependency, error) {
+	return s.AddSnapshotDependencyWithContext(context.Background(), dep)
+}
+
+//AddSnapshotDependencyWithContext adds a new snapshot dependency to build type, bound to ctx
+func (s *DependencyService) AddSnapshotDependencyWithContext(ctx context.Context, dep *SnapshotDependency) (*SnapshotDependency, error) {
 	var out SnapshotDependency
 	if dep == nil {
 		return nil, errors.New("dep can't be nil")
 	}
 
-	resp, err := s.snapshotSling.New().Post("").BodyJSON(dep).ReceiveSuccess(&out)
+	err := s.snapshotHelper.post(ctx, "", dep, &out, "snapshot dependency")
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Unknown error when adding snapshot dependency, statusCode: %d", resp.StatusCode)
-	}
 	out.BuildTypeID = s.BuildTypeID
 	return &out, nil
 }
 
 //AddArtifactDependency adds a new artifact dependency to build type
 func (s *DependencyService) AddArtifactDependency(dep *ArtifactDependency) (*ArtifactDependency, error) {
+	return s.AddArtifactDependencyWithContext(context.Background(), dep)
+}
+
+//AddArtifactDependencyWithContext adds a new artifact dependency to build type, bound to ctx
+func (s *DependencyService) AddArtifactDependencyWithContext(ctx context.Context, dep *ArtifactDependency) (*ArtifactDependency, error) {
 	var out ArtifactDependency
 	if dep == nil {
 		return nil, errors.New("dep can't be nil")
 	}
 
-	resp, err := s.artifactSling.New().Post("").BodyJSON(dep).ReceiveSuccess(&out)
+	err := s.artifactHelper.post(ctx, "", dep, &out, "artifact dependency")
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Unknown error when adding artifact dependency, statusCode: %d", resp.StatusCode)
-	}
-
 	out.SetBuildTypeID(s.BuildTypeID)
 	return &out, nil
 }
 
 //GetSnapshotByID returns a snapshot dependency by its id
 func (s *DependencyService) GetSnapshotByID(depID string) (*SnapshotDependency, error) {
-	var out SnapshotDependency
-	resp, err := s.snapshotSling.New().Get(depID).ReceiveSuccess(&out)
+	return s.GetSnapshotByIDWithContext(context.Background(), depID)
+}
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("404 Not Found - Snapshot dependency (id: %s) for buildTypeId (id: %s) was not found", depID, s.BuildTypeID)
-	}
+//GetSnapshotByIDWithContext returns a snapshot dependency by its id, bound to ctx
+func (s *DependencyService) GetSnapshotByIDWithContext(ctx context.Context, depID string) (*SnapshotDependency, error) {
+	var out SnapshotDependency
+	err := s.snapshotHelper.get(ctx, depID, &out, "snapshot dependency")
 
 	if err != nil {
 		return nil, err
@@ -92,8 +97,13 @@ func (s *DependencyService) GetSnapshotByID(depID string) (*SnapshotDependency,
 
 //GetArtifactByID returns an artifact dependency by its id
 func (s *DependencyService) GetArtifactByID(depID string) (*ArtifactDependency, error) {
+	return s.GetArtifactByIDWithContext(context.Background(), depID)
+}
+
+//GetArtifactByIDWithContext returns an artifact dependency by its id, bound to ctx
+func (s *DependencyService) GetArtifactByIDWithContext(ctx context.Context, depID string) (*ArtifactDependency, error) {
 	var out ArtifactDependency
-	err := s.artifactHelper.get(depID, &out, "artifact dependency")
+	err := s.artifactHelper.get(ctx, depID, &out, "artifact dependency")
 
 	if err != nil {
 		return nil, err
@@ -104,10 +114,20 @@ func (s *DependencyService) GetArtifactByID(depID string) (*ArtifactDependency,
 
 //DeleteSnapshot removes a snapshot dependency from the build configuration by its id
 func (s *DependencyService) DeleteSnapshot(depID string) error {
-	return s.snapshotHelper.deleteByIDWithSling(s.snapshotSling, depID, "snapshot dependency")
+	return s.DeleteSnapshotWithContext(context.Background(), depID)
+}
+
+//DeleteSnapshotWithContext removes a snapshot dependency from the build configuration by its id, bound to ctx
+func (s *DependencyService) DeleteSnapshotWithContext(ctx context.Context, depID string) error {
+	return s.snapshotHelper.deleteByIDWithSling(ctx, s.snapshotSling, depID, "snapshot dependency")
 }
 
 //DeleteArtifact removes an artifact dependency from the build configuration by its id
 func (s *DependencyService) DeleteArtifact(depID string) error {
-	return s.artifactHelper.deleteByIDWithSling(s.artifactSling, depID, "artifact dependency")
+	return s.DeleteArtifactWithContext(context.Background(), depID)
+}
+
+//DeleteArtifactWithContext removes an artifact dependency from the build configuration by its id, bound to ctx
+func (s *DependencyService) DeleteArtifactWithContext(ctx context.Context, depID string) error {
+	return s.artifactHelper.deleteByIDWithSling(ctx, s.artifactSling, depID, "artifact dependency")
 }
@@ -0,0 +1,68 @@
+package teamcity
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when TeamCity answers a request with an unexpected HTTP status.
+// Use errors.As to retrieve it, or the IsNotFound, IsConflict, IsUnauthorized and IsForbidden helpers.
+type APIError struct {
+	// StatusCode is the HTTP status code returned by the server
+	StatusCode int
+
+	// Method is the HTTP method of the failed request
+	Method string
+
+	// Resource describes what was being operated on, such as "project" or "build trigger"
+	Resource string
+
+	// Message is the error message returned by TeamCity in the response body
+	Message string
+
+	// URL is the request URL
+	URL string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Error '%d' when performing '%s' operation - %s: %s", e.StatusCode, e.Method, e.Resource, e.Message)
+}
+
+func newAPIError(response *http.Response, body []byte, resource string) *APIError {
+	err := &APIError{
+		StatusCode: response.StatusCode,
+		Resource:   resource,
+		Message:    string(body),
+	}
+	if response.Request != nil {
+		err.Method = response.Request.Method
+		err.URL = response.Request.URL.String()
+	}
+	return err
+}
+
+// IsNotFound reports whether err is an *APIError for a "404 Not Found" response
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an *APIError for a "409 Conflict" response
+func IsConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is an *APIError for a "401 Unauthorized" response
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an *APIError for a "403 Forbidden" response
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+func hasStatusCode(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,45 @@
+package teamcity
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_APIErrorFromRestHelper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("No project found by locator 'id:Missing'"))
+	}))
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+
+	_, err := client.Projects.GetByID("Missing")
+
+	require.Error(t, err)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "GET", apiErr.Method)
+	assert.Equal(t, "project", apiErr.Resource)
+	assert.Equal(t, "No project found by locator 'id:Missing'", apiErr.Message)
+	assert.Equal(t, server.URL+"/httpAuth/app/rest/projects/id%3AMissing", apiErr.URL)
+	assert.Equal(t, "Error '404' when performing 'GET' operation - project: No project found by locator 'id:Missing'", err.Error())
+}
+
+func Test_APIErrorHelpers(t *testing.T) {
+	wrapped := fmt.Errorf("wrapped: %w", &APIError{StatusCode: http.StatusConflict})
+
+	assert.True(t, IsConflict(wrapped))
+	assert.False(t, IsNotFound(wrapped))
+	assert.True(t, IsNotFound(&APIError{StatusCode: http.StatusNotFound}))
+	assert.True(t, IsUnauthorized(&APIError{StatusCode: http.StatusUnauthorized}))
+	assert.True(t, IsForbidden(&APIError{StatusCode: http.StatusForbidden}))
+	assert.False(t, IsNotFound(errors.New("404")))
+	assert.False(t, IsNotFound(nil))
+}
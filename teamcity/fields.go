@@ -0,0 +1,61 @@
+package teamcity
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Fields selects the fields of a partial response, such as "id,name,parameters(property(name,value))".
+// Read calls taking Fields only fetch what is selected, which is much faster than full representations
+// when reading many entities. Fields that are not selected are left empty in the result.
+type Fields string
+
+// Field sets for the lightweight representations of entities
+const (
+	// BuildTypeReferenceFields selects the fields of BuildTypeReference
+	BuildTypeReferenceFields Fields = "id,name,projectId"
+
+	// ProjectReferenceFields selects the fields of ProjectReference
+	ProjectReferenceFields Fields = "id,name,description,parentProjectId,href,webUrl"
+
+	// VcsRootReferenceFields selects the fields of VcsRootReference
+	VcsRootReferenceFields Fields = "id,name,href,project(id,name)"
+)
+
+// NewFields selects the given fields, without nested fields
+func NewFields(names ...string) Fields {
+	return Fields(strings.Join(names, ","))
+}
+
+// With adds fields to the selection
+func (f Fields) With(names ...string) Fields {
+	return f.join(NewFields(names...))
+}
+
+// Nested adds a field holding an entity or a collection, with the fields selected within it,
+// such as NewFields("id").Nested("parameters", NewFields().Nested("property", NewFields("name", "value")))
+func (f Fields) Nested(name string, sub Fields) Fields {
+	return f.join(Fields(name + "(" + string(sub) + ")"))
+}
+
+func (f Fields) join(other Fields) Fields {
+	if f == "" {
+		return other
+	}
+	if other == "" {
+		return f
+	}
+	return f + "," + other
+}
+
+func (f Fields) String() string {
+	return string(f)
+}
+
+// query returns the fields as a query string starting with "?", or the empty string to fetch full representations
+func (f Fields) query() string {
+	if f == "" {
+		return ""
+	}
+	return "?fields=" + url.QueryEscape(string(f))
+}
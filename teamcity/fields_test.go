@@ -0,0 +1,137 @@
+package teamcity
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFieldsClient answers every request with body, recording the fields requested
+func newFieldsClient(t *testing.T, body string, fields *[]string) (*Client, func()) {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*fields = append(*fields, r.URL.Path+" "+r.URL.Query().Get("fields"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+}
+
+func Test_FieldsNested(t *testing.T) {
+	sut := NewFields("id", "name").
+		Nested("parameters", NewFields().Nested("property", NewFields("name", "value"))).
+		With("paused")
+
+	assert.Equal(t, "id,name,parameters(property(name,value)),paused", sut.String())
+	assert.Equal(t, "?fields=id%2Cname%2Cparameters%28property%28name%2Cvalue%29%29%2Cpaused", sut.query())
+	assert.Equal(t, "", Fields("").query())
+}
+
+func Test_BuildTypeGetFieldsPartialResponse(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"id":"Project_Build","name":"Build","parameters":{"count":1,"property":[{"name":"env","value":"test"}]}}`, &fields)
+	defer done()
+
+	actual, err := client.BuildTypes.GetFields("Project_Build", NewFields("id", "name").Nested("parameters", NewFields().Nested("property", NewFields("name", "value"))))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/httpAuth/app/rest/buildTypes/Project_Build id,name,parameters(property(name,value))"}, fields)
+	assert.Equal(t, "Build", actual.Name)
+	assert.Nil(t, actual.Steps)
+	assert.Nil(t, actual.VcsRootEntries)
+	assert.Nil(t, actual.Options)
+	assert.EqualValues(t, 1, actual.Parameters.Count)
+}
+
+func Test_BuildTypeGetByIDDefaultsOptionsWithoutSettings(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"id":"Project_Build","name":"Build","projectId":"Project","parameters":{"count":0}}`, &fields)
+	defer done()
+
+	actual, err := client.BuildTypes.GetByID("Project_Build")
+
+	require.NoError(t, err)
+	require.NotNil(t, actual.Options)
+	assert.Equal(t, NewBuildTypeOptionsWithDefaults(), actual.Options)
+}
+
+func Test_BuildTypeListFieldsLeavesOptionsOfPartialResponses(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"count":2,"buildType":[{"id":"A_Build","name":"Build"},
+		{"id":"B_Test","name":"Test","settings":{"count":1,"property":[{"name":"maximumNumberOfBuilds","value":"3"}]}}]}`, &fields)
+	defer done()
+
+	actual, err := client.BuildTypes.ListFields(nil, NewFields("id", "name", "settings"))
+
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	assert.Nil(t, actual[0].Options)
+	require.NotNil(t, actual[1].Options)
+	assert.Equal(t, 3, actual[1].Options.MaxSimultaneousBuilds)
+}
+
+func Test_BuildTypeListReferences(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"count":2,"buildType":[{"id":"A_Build","name":"Build","projectId":"A"},{"id":"B_Test","name":"Test","projectId":"B"}]}`, &fields)
+	defer done()
+
+	actual, err := client.BuildTypes.ListReferences()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/httpAuth/app/rest/buildTypes/ count,buildType(id,name,projectId)"}, fields)
+	require.Len(t, actual, 2)
+	assert.Equal(t, BuildTypeReference{ID: "B_Test", Name: "Test", ProjectID: "B"}, *actual[1])
+}
+
+func Test_ProjectListReferences(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"count":1,"project":[{"id":"A","name":"A","parentProjectId":"_Root"}]}`, &fields)
+	defer done()
+
+	actual, err := client.Projects.ListReferences()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/httpAuth/app/rest/projects/ count,project(" + string(ProjectReferenceFields) + ")"}, fields)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "_Root", actual[0].ParentProjectID)
+}
+
+func Test_BuildGetFields(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"id":7,"status":"SUCCESS"}`, &fields)
+	defer done()
+
+	actual, err := client.Builds.GetFields(7, NewFields("id", "status"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/httpAuth/app/rest/builds/id:7 id,status"}, fields)
+	assert.Equal(t, BuildStatuses.Success, actual.Status)
+	assert.Nil(t, actual.BuildType)
+}
+
+func Test_AgentGetFields(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"id":3,"name":"agent-1","pool":{"id":0}}`, &fields)
+	defer done()
+
+	actual, err := client.Agents.GetFields(3, NewFields("id", "name").Nested("pool", NewFields("id")))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/httpAuth/app/rest/agents/id:3 id,name,pool(id)"}, fields)
+	assert.Equal(t, "agent-1", actual.Name)
+	assert.Nil(t, actual.Properties)
+}
+
+func Test_VcsRootListReferences(t *testing.T) {
+	var fields []string
+	client, done := newFieldsClient(t, `{"count":1,"vcs-root":[{"id":"A_Repo","name":"Repo","project":{"id":"A"}}]}`, &fields)
+	defer done()
+
+	actual, err := client.VcsRoots.ListReferences()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/httpAuth/app/rest/vcs-roots/ count,vcs-root(" + string(VcsRootReferenceFields) + ")"}, fields)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "A", actual[0].Project.ID)
+}
@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -12,6 +13,18 @@ type Group struct {
 	Key         string `json:"key,omitempty" xml:"key"`
 	Description string `json:"description,omitempty" xml:"description"`
 	Name        string `json:"name,omitempty" xml:"name"`
+
+	// ParentGroups, ChildGroups and Users are only returned by the server, use SetParentGroups and UserService.AddToGroup to change them
+	ParentGroups *Groups `json:"parent-groups,omitempty"`
+	ChildGroups  *Groups `json:"child-groups,omitempty"`
+	Users        *Users  `json:"users,omitempty"`
+}
+
+// Groups is a collection of Group
+type Groups struct {
+	Count    int      `json:"count,omitempty" xml:"count"`
+	NextHref string   `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*Group `json:"group"`
 }
 
 // NewGroup returns an instance of a Group. A non-empty Key and Name is required.
@@ -50,8 +63,13 @@ func newGroupService(base *sling.Sling, httpClient *http.Client) *GroupService {
 
 // Create - Creates a new group
 func (s *GroupService) Create(group *Group) (*Group, error) {
+	return s.CreateWithContext(context.Background(), group)
+}
+
+// CreateWithContext - Creates a new group, bound to ctx
+func (s *GroupService) CreateWithContext(ctx context.Context, group *Group) (*Group, error) {
 	var created Group
-	err := s.restHelper.post("", group, &created, "group")
+	err := s.restHelper.post(ctx, "", group, &created, "group")
 
 	if err != nil {
 		return nil, err
@@ -62,9 +80,14 @@ func (s *GroupService) Create(group *Group) (*Group, error) {
 
 // GetByKey - Get a group by its group key
 func (s *GroupService) GetByKey(key string) (*Group, error) {
+	return s.GetByKeyWithContext(context.Background(), key)
+}
+
+// GetByKeyWithContext - Get a group by its group key, bound to ctx
+func (s *GroupService) GetByKeyWithContext(ctx context.Context, key string) (*Group, error) {
 	var out Group
 	locator := LocatorKey(key).String()
-	err := s.restHelper.get(locator, &out, "group")
+	err := s.restHelper.get(ctx, locator, &out, "group")
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +97,140 @@ func (s *GroupService) GetByKey(key string) (*Group, error) {
 
 // Delete - Deletes a group by its group key
 func (s *GroupService) Delete(key string) error {
+	return s.DeleteWithContext(context.Background(), key)
+}
+
+// DeleteWithContext - Deletes a group by its group key, bound to ctx
+func (s *GroupService) DeleteWithContext(ctx context.Context, key string) error {
 	locator := LocatorKey(key).String()
-	err := s.restHelper.delete(locator, "group")
+	err := s.restHelper.delete(ctx, locator, "group")
 	return err
 }
+
+// List - Lists all groups
+func (s *GroupService) List() ([]*Group, error) {
+	return s.ListWithContext(context.Background())
+}
+
+// ListWithContext - Lists all groups, bound to ctx
+func (s *GroupService) ListWithContext(ctx context.Context) ([]*Group, error) {
+	var out Groups
+	err := s.restHelper.get(ctx, "", &out, "groups")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate - Walks all groups, fetching pageSize groups per request. Use DefaultPageSize when pageSize is not positive.
+func (s *GroupService) Iterate(pageSize int) *GroupIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
+
+// IterateWithContext - Walks all groups page by page, bound to ctx
+func (s *GroupService) IterateWithContext(ctx context.Context, pageSize int) *GroupIterator {
+	return newGroupIterator(ctx, s.restHelper, "?locator="+pagedLocator("", pageSize).String())
+}
+
+// Update - Changes the name and description of an existing group, identified by its key.
+// An empty description leaves the current description unchanged.
+func (s *GroupService) Update(group *Group) (*Group, error) {
+	return s.UpdateWithContext(context.Background(), group)
+}
+
+// UpdateWithContext - Changes the name and description of an existing group, bound to ctx
+func (s *GroupService) UpdateWithContext(ctx context.Context, group *Group) (*Group, error) {
+	if group.Name == "" {
+		return nil, fmt.Errorf("Name is required")
+	}
+
+	locator := LocatorKey(group.Key).String()
+	_, err := s.restHelper.putTextPlain(ctx, locator+"/name", group.Name, "group name")
+	if err != nil {
+		return nil, err
+	}
+	// The description is optional: an empty one is left unchanged rather than sent
+	if group.Description != "" {
+		_, err = s.restHelper.putTextPlain(ctx, locator+"/description", group.Description, "group description")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetByKeyWithContext(ctx, group.Key)
+}
+
+// GetParentGroups - Get the groups a group is a direct child of
+func (s *GroupService) GetParentGroups(key string) ([]*Group, error) {
+	return s.GetParentGroupsWithContext(context.Background(), key)
+}
+
+// GetParentGroupsWithContext - Get the groups a group is a direct child of, bound to ctx
+func (s *GroupService) GetParentGroupsWithContext(ctx context.Context, key string) ([]*Group, error) {
+	var out Groups
+	err := s.restHelper.get(ctx, LocatorKey(key).String()+"/parent-groups", &out, "parent groups")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// SetParentGroups - Replaces the parent groups of a group with the groups of the given keys.
+// Members of a group inherit the roles of all its parent groups. An empty list makes the group a top level group.
+func (s *GroupService) SetParentGroups(key string, parentKeys []string) ([]*Group, error) {
+	return s.SetParentGroupsWithContext(context.Background(), key, parentKeys)
+}
+
+// SetParentGroupsWithContext - Replaces the parent groups of a group with the groups of the given keys, bound to ctx
+func (s *GroupService) SetParentGroupsWithContext(ctx context.Context, key string, parentKeys []string) ([]*Group, error) {
+	request := &Groups{Count: len(parentKeys), Items: make([]*Group, 0, len(parentKeys))}
+	for _, k := range parentKeys {
+		request.Items = append(request.Items, &Group{Key: k})
+	}
+
+	var out Groups
+	err := s.restHelper.put(ctx, LocatorKey(key).String()+"/parent-groups", request, &out, "parent groups")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// GetChildGroups - Get the groups that are direct children of a group
+func (s *GroupService) GetChildGroups(key string) ([]*Group, error) {
+	return s.GetChildGroupsWithContext(context.Background(), key)
+}
+
+// GetChildGroupsWithContext - Get the groups that are direct children of a group, bound to ctx
+func (s *GroupService) GetChildGroupsWithContext(ctx context.Context, key string) ([]*Group, error) {
+	group, err := s.GetByKeyWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if group.ChildGroups == nil {
+		return nil, nil
+	}
+
+	return group.ChildGroups.Items, nil
+}
+
+// GetUsers - Get the users that are direct members of a group
+func (s *GroupService) GetUsers(key string) ([]*User, error) {
+	return s.GetUsersWithContext(context.Background(), key)
+}
+
+// GetUsersWithContext - Get the users that are direct members of a group, bound to ctx
+func (s *GroupService) GetUsersWithContext(ctx context.Context, key string) ([]*User, error) {
+	group, err := s.GetByKeyWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if group.Users == nil {
+		return nil, nil
+	}
+
+	return group.Users.Items, nil
+}
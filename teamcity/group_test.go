@@ -56,6 +56,81 @@ func TestGroup_Delete(t *testing.T) {
 	// Group is deleted, so expect error, and message to contain 404 (NOT FOUND)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "404")
+	assert.True(t, teamcity.IsNotFound(err))
+}
+
+func TestGroup_List(t *testing.T) {
+	newGroup, _ := teamcity.NewGroup("TESTGROUPKEY", "Test Group Name", "")
+	client := setup()
+	client.Groups.Create(newGroup)
+	defer cleanUpGroup(t, client, newGroup.Key)
+
+	actual, err := client.Groups.List()
+
+	require.NoError(t, err)
+	keys := make([]string, 0, len(actual))
+	for _, g := range actual {
+		keys = append(keys, g.Key)
+	}
+	assert.Contains(t, keys, newGroup.Key)
+	assert.Contains(t, keys, teamcity.AllUsersGroupKey)
+}
+
+func TestGroup_Update(t *testing.T) {
+	newGroup, _ := teamcity.NewGroup("TESTGROUPKEY", "Test Group Name", "Test Group Description")
+	client := setup()
+	client.Groups.Create(newGroup)
+	defer cleanUpGroup(t, client, newGroup.Key)
+
+	newGroup.Name = "Renamed Group"
+	newGroup.Description = "Updated Description"
+	actual, err := client.Groups.Update(newGroup)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed Group", actual.Name)
+	assert.Equal(t, "Updated Description", actual.Description)
+}
+
+func TestGroup_Hierarchy(t *testing.T) {
+	parent, _ := teamcity.NewGroup("TESTPARENTKEY", "Test Parent Group", "")
+	child, _ := teamcity.NewGroup("TESTCHILDKEY", "Test Child Group", "")
+	client := setup()
+	client.Groups.Create(parent)
+	defer cleanUpGroup(t, client, parent.Key)
+	client.Groups.Create(child)
+	defer cleanUpGroup(t, client, child.Key)
+
+	parents, err := client.Groups.SetParentGroups(child.Key, []string{parent.Key})
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+	assert.Equal(t, parent.Key, parents[0].Key)
+
+	parents, err = client.Groups.GetParentGroups(child.Key)
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+	assert.Equal(t, parent.Key, parents[0].Key)
+
+	children, err := client.Groups.GetChildGroups(parent.Key)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, child.Key, children[0].Key)
+}
+
+func TestGroup_GetUsers(t *testing.T) {
+	newGroup, _ := teamcity.NewGroup("TESTGROUPKEY", "Test Group Name", "")
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "", "P@ssw0rd")
+	client := setup()
+	client.Groups.Create(newGroup)
+	defer cleanUpGroup(t, client, newGroup.Key)
+	client.Users.Create(newUser)
+	defer cleanUpUser(t, client, newUser.Username)
+	client.Users.AddToGroup(newUser.Username, newGroup.Key)
+
+	actual, err := client.Groups.GetUsers(newGroup.Key)
+
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, newUser.Username, actual[0].Username)
 }
 
 func cleanUpGroup(t *testing.T, client *teamcity.Client, key string) {
@@ -0,0 +1,43 @@
+package teamcity
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient starts a server answering with handler, and returns a client for it with a function closing the server
+func newTestClient(t *testing.T, handler http.Handler) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	client, err := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+	return client, server.Close
+}
+
+// recordedRequest is a request received by a test server
+type recordedRequest struct {
+	Method string
+	URI    string
+	Body   string
+}
+
+func recordRequest(r *http.Request) recordedRequest {
+	body, _ := ioutil.ReadAll(r.Body)
+	return recordedRequest{Method: r.Method, URI: r.URL.RequestURI(), Body: string(body)}
+}
+
+// recordingHandler records requests, answering DELETE with 204 No Content and other methods with the JSON response
+func recordingHandler(response string, requests *[]recordedRequest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, recordRequest(r))
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}
+}
@@ -1,8 +1,11 @@
 package teamcity
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 )
 
 //Locator represents a arbitraty locator to be used when querying resources, such as id:, type:, or key:
@@ -29,6 +32,16 @@ func LocatorKey(key string) Locator {
 	return Locator(url.QueryEscape("key:") + url.PathEscape(key))
 }
 
+//LocatorUsername creates a locator for a User by Username
+func LocatorUsername(username string) Locator {
+	return Locator(url.QueryEscape("username:") + url.PathEscape(username))
+}
+
+//LocatorEmail creates a locator for a User by Email
+func LocatorEmail(email string) Locator {
+	return Locator(url.QueryEscape("email:") + url.PathEscape(email))
+}
+
 //LocatorType creates a locator for a Project Feature by Type
 func LocatorType(id string) Locator {
 	return Locator(url.QueryEscape("type:") + id)
@@ -37,3 +50,239 @@ func LocatorType(id string) Locator {
 func (l Locator) String() string {
 	return string(l)
 }
+
+//LocatorDimension is one dimension of a locator, such as "count:10". Name is empty for a locator made of a single value.
+//Value is unescaped: for a nested locator such as "project:(id:X)", it holds "id:X".
+type LocatorDimension struct {
+	Name  string
+	Value string
+}
+
+//LocatorBuilder composes locators of any number of dimensions, such as "project:(id:X),branch:(default:any),status:FAILURE,count:10".
+//Dimensions are kept in the order they are added, and may repeat, as "tag" does.
+//Values are escaped as TeamCity expects them: values holding commas, colons or parentheses are wrapped in parentheses,
+//and values with unbalanced parentheses are base64 encoded.
+type LocatorBuilder struct {
+	dims []LocatorDimension
+}
+
+//NewLocatorBuilder returns an empty LocatorBuilder
+func NewLocatorBuilder() *LocatorBuilder {
+	return &LocatorBuilder{}
+}
+
+//Dimension adds a dimension with a plain value
+func (b *LocatorBuilder) Dimension(name string, value string) *LocatorBuilder {
+	b.dims = append(b.dims, LocatorDimension{Name: name, Value: value})
+	return b
+}
+
+//Sub adds a dimension holding a nested locator, such as "project:(id:X)"
+func (b *LocatorBuilder) Sub(name string, sub *LocatorBuilder) *LocatorBuilder {
+	b.dims = append(b.dims, LocatorDimension{Name: name, Value: sub.String()})
+	return b
+}
+
+//ID adds the "id" dimension
+func (b *LocatorBuilder) ID(id string) *LocatorBuilder {
+	return b.Dimension("id", id)
+}
+
+//Name adds the "name" dimension
+func (b *LocatorBuilder) Name(name string) *LocatorBuilder {
+	return b.Dimension("name", name)
+}
+
+//Key adds the "key" dimension, used by groups
+func (b *LocatorBuilder) Key(key string) *LocatorBuilder {
+	return b.Dimension("key", key)
+}
+
+//Type adds the "type" dimension, used by features
+func (b *LocatorBuilder) Type(t string) *LocatorBuilder {
+	return b.Dimension("type", t)
+}
+
+//Status adds the "status" dimension, one of BuildStatuses for builds
+func (b *LocatorBuilder) Status(status string) *LocatorBuilder {
+	return b.Dimension("status", status)
+}
+
+//State adds the "state" dimension, one of BuildStates for builds
+func (b *LocatorBuilder) State(state string) *LocatorBuilder {
+	return b.Dimension("state", state)
+}
+
+//Tag adds a "tag" dimension. Builds must have all the tags added.
+func (b *LocatorBuilder) Tag(tag string) *LocatorBuilder {
+	return b.Dimension("tag", tag)
+}
+
+//Count adds the "count" dimension, limiting the number of items returned
+func (b *LocatorBuilder) Count(count int) *LocatorBuilder {
+	return b.Dimension("count", strconv.Itoa(count))
+}
+
+//Start adds the "start" dimension, skipping the first items
+func (b *LocatorBuilder) Start(start int) *LocatorBuilder {
+	return b.Dimension("start", strconv.Itoa(start))
+}
+
+//Project adds the "project" dimension, selecting by project
+func (b *LocatorBuilder) Project(project *LocatorBuilder) *LocatorBuilder {
+	return b.Sub("project", project)
+}
+
+//AffectedProject adds the "affectedProject" dimension, selecting by project or any of its subprojects
+func (b *LocatorBuilder) AffectedProject(project *LocatorBuilder) *LocatorBuilder {
+	return b.Sub("affectedProject", project)
+}
+
+//BuildType adds the "buildType" dimension, selecting by build configuration
+func (b *LocatorBuilder) BuildType(buildType *LocatorBuilder) *LocatorBuilder {
+	return b.Sub("buildType", buildType)
+}
+
+//Branch adds the "branch" dimension, such as NewLocatorBuilder().Dimension("default", "any") for builds of all branches
+func (b *LocatorBuilder) Branch(branch *LocatorBuilder) *LocatorBuilder {
+	return b.Sub("branch", branch)
+}
+
+//Agent adds the "agent" dimension, selecting by build agent
+func (b *LocatorBuilder) Agent(agent *LocatorBuilder) *LocatorBuilder {
+	return b.Sub("agent", agent)
+}
+
+//Pool adds the "pool" dimension, selecting by agent pool
+func (b *LocatorBuilder) Pool(pool *LocatorBuilder) *LocatorBuilder {
+	return b.Sub("pool", pool)
+}
+
+//Dimensions returns the dimensions of the locator, in order
+func (b *LocatorBuilder) Dimensions() []LocatorDimension {
+	return append([]LocatorDimension(nil), b.dims...)
+}
+
+//Lookup returns the value of the first dimension with the given name
+func (b *LocatorBuilder) Lookup(name string) (string, bool) {
+	for _, d := range b.dims {
+		if d.Name == name {
+			return d.Value, true
+		}
+	}
+	return "", false
+}
+
+//LookupSub parses the value of the first dimension with the given name as a nested locator
+func (b *LocatorBuilder) LookupSub(name string) (*LocatorBuilder, bool, error) {
+	value, ok := b.Lookup(name)
+	if !ok {
+		return nil, false, nil
+	}
+	sub, err := ParseLocator(value)
+	return sub, true, err
+}
+
+//String returns the locator unescaped for URLs, as it appears in the TeamCity documentation
+func (b *LocatorBuilder) String() string {
+	parts := make([]string, len(b.dims))
+	for i, d := range b.dims {
+		if d.Name == "" {
+			parts[i] = escapeLocatorValue(d.Value)
+		} else {
+			parts[i] = d.Name + ":" + escapeLocatorValue(d.Value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+//Locator returns the locator escaped to be used in URLs, either as a path segment or as a query value.
+//Spaces are escaped as %20 rather than +, which a path segment would keep as a literal plus sign.
+func (b *LocatorBuilder) Locator() Locator {
+	return Locator(strings.Replace(url.QueryEscape(b.String()), "+", "%20", -1))
+}
+
+//ParseLocator parses an unescaped locator, such as "project:(id:X),count:10", into its dimensions.
+//A locator made of a single value, such as "X", has one dimension with an empty name.
+func ParseLocator(locator string) (*LocatorBuilder, error) {
+	b := NewLocatorBuilder()
+	if locator == "" {
+		return b, nil
+	}
+
+	depth, start := 0, 0
+	for i := 0; i <= len(locator); i++ {
+		if i < len(locator) {
+			switch locator[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth < 0 {
+					return nil, fmt.Errorf("invalid locator %q: unexpected ')' at %d", locator, i)
+				}
+			}
+			if locator[i] != ',' || depth > 0 {
+				continue
+			}
+		}
+		if depth > 0 {
+			return nil, fmt.Errorf("invalid locator %q: missing ')'", locator)
+		}
+
+		dim, err := parseLocatorDimension(locator[start:i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid locator %q: %s", locator, err)
+		}
+		b.dims = append(b.dims, dim)
+		start = i + 1
+	}
+	return b, nil
+}
+
+func parseLocatorDimension(s string) (LocatorDimension, error) {
+	var dim LocatorDimension
+	if colon := strings.Index(s, ":"); colon >= 0 && !strings.HasPrefix(s, "(") {
+		dim.Name, s = s[:colon], s[colon+1:]
+		if dim.Name == "" || strings.ContainsAny(dim.Name, "(),") {
+			return dim, fmt.Errorf("invalid dimension name %q", dim.Name)
+		}
+	}
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = s[1 : len(s)-1]
+		if strings.HasPrefix(s, base64Prefix) {
+			decoded, err := base64.StdEncoding.DecodeString(s[len(base64Prefix):])
+			if err != nil {
+				return dim, fmt.Errorf("invalid base64 value of dimension %q: %s", dim.Name, err)
+			}
+			s = string(decoded)
+		}
+	}
+	dim.Value = s
+	return dim, nil
+}
+
+//base64Prefix marks values TeamCity decodes from base64, for values parentheses cannot wrap
+const base64Prefix = "$base64:"
+
+func escapeLocatorValue(value string) string {
+	if !strings.ContainsAny(value, ",:()") && !strings.HasPrefix(value, base64Prefix) {
+		return value
+	}
+	depth := 0
+	for _, c := range value {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 || strings.HasPrefix(value, base64Prefix) {
+		return "(" + base64Prefix + base64.StdEncoding.EncodeToString([]byte(value)) + ")"
+	}
+	return "(" + value + ")"
+}
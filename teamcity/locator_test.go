@@ -1,9 +1,13 @@
 package teamcity
 
 import (
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_LocatorNameWithSpaces(t *testing.T) {
@@ -19,3 +23,136 @@ func Test_LocatorId(t *testing.T) {
 
 	assert.Equal(t, "id%3A_Root", actual)
 }
+
+func Test_LocatorEmail(t *testing.T) {
+	sut := LocatorEmail("jane.doe+ci@example.com")
+	actual := sut.String()
+
+	assert.Equal(t, "email%3Ajane.doe+ci@example.com", actual)
+}
+
+func Test_BuildFilterLocator(t *testing.T) {
+	sut := &BuildFilter{
+		BuildTypeID: "Project_Build",
+		Branch:      "default:any",
+		Status:      BuildStatuses.Failure,
+		State:       BuildStates.Finished,
+		Since:       time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:        []string{"release"},
+		Count:       10,
+	}
+	actual := sut.locator().String()
+
+	assert.Equal(t, "buildType%3A%28id%3AProject_Build%29%2Cbranch%3A%28default%3Aany%29%2Cstatus%3AFAILURE%2Cstate%3Afinished%2CsinceDate%3A20200102T030405%2B0000%2Ctag%3Arelease%2Ccount%3A10", actual)
+}
+
+func Test_BuildFilterLocatorEscapesValues(t *testing.T) {
+	sut := &BuildFilter{
+		BuildTypeID: "Project_Build",
+		Branch:      "feature/a,b",
+		Tags:        []string{"v1 (rc", "done"},
+	}
+	actual, err := url.QueryUnescape(sut.locator().String())
+	require.NoError(t, err)
+
+	assert.Equal(t, "buildType:(id:Project_Build),branch:(name:(feature/a,b)),tag:($base64:djEgKHJj),tag:done", actual)
+	parsed, err := ParseLocator(actual)
+	require.NoError(t, err)
+	branch, _, err := parsed.LookupSub("branch")
+	require.NoError(t, err)
+	name, _ := branch.Lookup("name")
+	assert.Equal(t, "feature/a,b", name)
+	tag, _ := parsed.Lookup("tag")
+	assert.Equal(t, "v1 (rc", tag)
+}
+
+func Test_BuildFilterLocatorEmpty(t *testing.T) {
+	sut := &BuildFilter{}
+
+	assert.Equal(t, "", sut.locator().String())
+}
+
+func Test_LocatorBuilderMultipleDimensions(t *testing.T) {
+	sut := NewLocatorBuilder().
+		Project(NewLocatorBuilder().ID("X")).
+		Branch(NewLocatorBuilder().Dimension("default", "any")).
+		Status(BuildStatuses.Failure).
+		Count(10)
+
+	assert.Equal(t, "project:(id:X),branch:(default:any),status:FAILURE,count:10", sut.String())
+	assert.Equal(t, "project%3A%28id%3AX%29%2Cbranch%3A%28default%3Aany%29%2Cstatus%3AFAILURE%2Ccount%3A10", sut.Locator().String())
+}
+
+func Test_LocatorBuilderEscapesValues(t *testing.T) {
+	sut := NewLocatorBuilder().
+		Name("Build, test: (all)").
+		Tag("plain").
+		Dimension("text", "unbalanced)(")
+
+	assert.Equal(t, "name:(Build, test: (all)),tag:plain,text:($base64:dW5iYWxhbmNlZCko)", sut.String())
+}
+
+func Test_LocatorBuilderSpacesInPathAndQuery(t *testing.T) {
+	var path, query string
+	client, done := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.Query().Get("locator")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"count":0}`))
+	}))
+	defer done()
+
+	locator := NewLocatorBuilder().Dimension("number", "1.0 rc+1").Locator()
+	assert.Equal(t, "number%3A1.0%20rc%2B1", locator.String())
+
+	_, err := client.Builds.GetByLocator(locator)
+	require.NoError(t, err)
+	assert.Equal(t, "/httpAuth/app/rest/builds/number:1.0 rc+1", path)
+
+	_, err = client.BuildTypes.List(&BuildTypeFilter{ProjectID: "My Project"})
+	require.NoError(t, err)
+	assert.Equal(t, "project:(id:My Project),templateFlag:any", query)
+}
+
+func Test_ParseLocator(t *testing.T) {
+	sut, err := ParseLocator("buildType:(project:(id:X),name:(a,b)),tag:one,tag:two,text:($base64:dW5iYWxhbmNlZCko)")
+	require.NoError(t, err)
+
+	assert.Equal(t, []LocatorDimension{
+		{Name: "buildType", Value: "project:(id:X),name:(a,b)"},
+		{Name: "tag", Value: "one"},
+		{Name: "tag", Value: "two"},
+		{Name: "text", Value: "unbalanced)("},
+	}, sut.Dimensions())
+
+	buildType, ok, err := sut.LookupSub("buildType")
+	require.NoError(t, err)
+	require.True(t, ok)
+	project, _, err := buildType.LookupSub("project")
+	require.NoError(t, err)
+	id, _ := project.Lookup("id")
+	assert.Equal(t, "X", id)
+	name, _ := buildType.Lookup("name")
+	assert.Equal(t, "a,b", name)
+}
+
+func Test_ParseLocatorRoundTrip(t *testing.T) {
+	for _, locator := range []string{
+		"",
+		"_Root",
+		"id:_Root",
+		"project:(id:X),branch:(default:any),status:FAILURE,count:10",
+		"name:(Build, test: (all)),text:($base64:dW5iYWxhbmNlZCko)",
+	} {
+		sut, err := ParseLocator(locator)
+		require.NoError(t, err, locator)
+		assert.Equal(t, locator, sut.String())
+	}
+}
+
+func Test_ParseLocatorInvalid(t *testing.T) {
+	for _, locator := range []string{"project:(id:X", "id:X)", ":X", "text:($base64:!)"} {
+		_, err := ParseLocator(locator)
+		assert.Error(t, err, locator)
+	}
+}
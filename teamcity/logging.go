@@ -0,0 +1,207 @@
+package teamcity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Logger receives a debug record for every request sent by a Client and its response.
+// Args are alternating keys and values. *slog.Logger implements it.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// Redacted replaces secrets, such as credentials and password properties, in logged requests and responses
+const Redacted = "[REDACTED]"
+
+// maxLoggedBody bounds how much of a body is logged, so that streamed logs and artifacts are not buffered in memory
+const maxLoggedBody = 64 * 1024
+
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", CSRFHeader}
+
+// sensitiveName matches names of fields and properties holding secrets
+var sensitiveName = regexp.MustCompile(`(?i)password|secret|^secure:`)
+
+type requestIDKey struct{}
+
+// logger returns the logger requests are traced with, or nil when tracing is off
+func (c *Client) logger() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if DebugRequests || DebugResponses {
+		return stdoutLogger{}
+	}
+	return nil
+}
+
+func (c *Client) logBodies() bool {
+	return c.LogBodies || (c.Logger == nil && (DebugRequests || DebugResponses))
+}
+
+// withRequestID tags the context of req with a new id, shared by all the attempts to send it
+func (t *clientTransport) withRequestID(req *http.Request) *http.Request {
+	if t.client.logger() == nil {
+		return req
+	}
+	id := atomic.AddUint64(&t.client.requestCount, 1)
+	return req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+}
+
+// doLogged sends req through the caller's http client, tracing it with the client's logger
+func (t *clientTransport) doLogged(req *http.Request) (*http.Response, error) {
+	logger := t.client.logger()
+	if logger == nil {
+		return t.client.httpClient().Do(req)
+	}
+
+	ctx := req.Context()
+	id, _ := ctx.Value(requestIDKey{}).(uint64)
+	args := []interface{}{"request_id", id, "method", req.Method, "url", req.URL.String(), "headers", RedactHeaders(req.Header)}
+	if t.client.logBodies() {
+		args = append(args, "body", requestBodyForLog(req))
+	}
+	if t.client.Logger != nil || DebugRequests {
+		logger.DebugContext(ctx, "teamcity request", args...)
+	}
+
+	started := time.Now()
+	resp, err := t.client.httpClient().Do(req)
+	elapsed := time.Since(started)
+	if err != nil {
+		logger.DebugContext(ctx, "teamcity request failed", "request_id", id, "method", req.Method, "url", req.URL.String(), "duration", elapsed, "error", err)
+		return resp, err
+	}
+
+	if t.client.Logger != nil || DebugResponses {
+		args = []interface{}{"request_id", id, "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "duration", elapsed, "headers", RedactHeaders(resp.Header)}
+		if t.client.logBodies() {
+			args = append(args, "body", responseBodyForLog(req, resp))
+		}
+		logger.DebugContext(ctx, "teamcity response", args...)
+	}
+	return resp, nil
+}
+
+// RedactHeaders returns a copy of header with the values of credential headers, such as Authorization and cookies, replaced by Redacted.
+// It is used for logging, and can be used by anything else that stores or prints exchanges with the server.
+func RedactHeaders(header http.Header) http.Header {
+	out := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := out[name]; ok {
+			out[name] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+func requestBodyForLog(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if req.GetBody == nil {
+		return "[body not logged]"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "[body not logged]"
+	}
+	defer body.Close()
+	data, _ := ioutil.ReadAll(io.LimitReader(body, maxLoggedBody))
+	return RedactBody(req, data)
+}
+
+// responseBodyForLog reads the start of the body of resp for logging, leaving the whole body readable by the caller
+func responseBodyForLog(req *http.Request, resp *http.Response) string {
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	resp.Body = readCloser{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return RedactBody(req, data)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RedactBody hides secrets in a body sent to or received for req: values of password fields and properties, token values,
+// and plain text sent to password endpoints
+func RedactBody(req *http.Request, data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		if sensitiveName.MatchString(segment) {
+			return Redacted
+		}
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			// Truncated or malformed JSON cannot be redacted reliably
+			return "[body not logged]"
+		}
+		return string(data)
+	}
+	redactJSON(body, strings.Contains(req.URL.Path, "/tokens"))
+	out, err := json.Marshal(body)
+	if err != nil {
+		return Redacted
+	}
+	return string(out)
+}
+
+func redactJSON(v interface{}, tokens bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			redactJSON(item, tokens)
+		}
+	case map[string]interface{}:
+		if _, ok := v["value"]; ok && (isSensitiveProperty(v) || tokens) {
+			v["value"] = Redacted
+		}
+		for key, item := range v {
+			if _, isString := item.(string); isString && sensitiveName.MatchString(key) {
+				v[key] = Redacted
+				continue
+			}
+			redactJSON(item, tokens)
+		}
+	}
+}
+
+// isSensitiveProperty reports whether a property or parameter holds a secret, by its name or its password type
+func isSensitiveProperty(p map[string]interface{}) bool {
+	if name, ok := p["name"].(string); ok && sensitiveName.MatchString(name) {
+		return true
+	}
+	if t, ok := p["type"].(map[string]interface{}); ok {
+		if spec, ok := t["rawValue"].(string); ok && strings.HasPrefix(spec, "password") {
+			return true
+		}
+	}
+	return false
+}
+
+// stdoutLogger prints to stdout, for the deprecated DebugRequests and DebugResponses toggles
+type stdoutLogger struct{}
+
+func (stdoutLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	fmt.Fprintf(os.Stdout, "%s\n\n", b.String())
+}
@@ -0,0 +1,116 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedLog struct {
+	Msg  string
+	Args map[string]interface{}
+}
+
+type recordingLogger struct {
+	records []recordedLog
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	record := recordedLog{Msg: msg, Args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		record.Args[fmt.Sprint(args[i])] = args[i+1]
+	}
+	l.records = append(l.records, record)
+}
+
+func newLoggingTestClient(t *testing.T, response string) (*Client, *recordingLogger, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	client, err := NewClientWithAddress(BasicAuth("admin", "secret"), server.URL, http.DefaultClient)
+	require.NoError(t, err)
+	logger := &recordingLogger{}
+	client.Logger = logger
+	return client, logger, server.Close
+}
+
+func Test_LoggerTracesRequestAndResponse(t *testing.T) {
+	client, logger, done := newLoggingTestClient(t, `{"id":"Project1","name":"Project 1"}`)
+	defer done()
+
+	_, err := client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+	_, err = client.Projects.GetByID("Project1")
+	require.NoError(t, err)
+
+	require.Len(t, logger.records, 4)
+	request, response := logger.records[0], logger.records[1]
+	assert.Equal(t, "teamcity request", request.Msg)
+	assert.Equal(t, "GET", request.Args["method"])
+	assert.Equal(t, Redacted, request.Args["headers"].(http.Header).Get("Authorization"))
+	assert.NotContains(t, request.Args, "body")
+	assert.Equal(t, "teamcity response", response.Msg)
+	assert.Equal(t, 200, response.Args["status"])
+	assert.IsType(t, time.Duration(0), response.Args["duration"])
+	assert.Equal(t, request.Args["request_id"], response.Args["request_id"])
+	assert.NotEqual(t, request.Args["request_id"], logger.records[2].Args["request_id"])
+}
+
+func Test_LoggerRedactsPasswordsInBodies(t *testing.T) {
+	client, logger, done := newLoggingTestClient(t, `{"id":"Root_Git","properties":{"property":[
+		{"name":"url","value":"https://example.com/repo.git"},
+		{"name":"secure:password","value":"s3cr3t"}
+	]},"parameters":{"property":[
+		{"name":"deploy.key","value":"k3y","type":{"rawValue":"password display='hidden'"}}
+	]}}`)
+	defer done()
+	client.LogBodies = true
+
+	user, _ := NewUser("jdoe", "", "", "hunter2")
+	_, err := client.Users.Create(user)
+	require.NoError(t, err)
+
+	requestBody := logger.records[0].Args["body"].(string)
+	assert.Contains(t, requestBody, "jdoe")
+	assert.NotContains(t, requestBody, "hunter2")
+	responseBody := logger.records[1].Args["body"].(string)
+	assert.Contains(t, responseBody, "https://example.com/repo.git")
+	assert.NotContains(t, responseBody, "s3cr3t")
+	assert.NotContains(t, responseBody, "k3y")
+}
+
+func Test_LoggerRedactsPlainTextPasswords(t *testing.T) {
+	client, logger, done := newLoggingTestClient(t, ``)
+	defer done()
+	client.LogBodies = true
+
+	err := client.Users.SetPassword("jdoe", "hunter2")
+	require.NoError(t, err)
+
+	assert.Equal(t, Redacted, logger.records[0].Args["body"])
+}
+
+func Test_LoggerKeepsResponseBodyReadable(t *testing.T) {
+	client, logger, done := newLoggingTestClient(t, `{"id":"Project1","name":"Project 1"}`)
+	defer done()
+	client.LogBodies = true
+
+	actual, err := client.Projects.GetByID("Project1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Project 1", actual.Name)
+	assert.Contains(t, logger.records[1].Args["body"], "Project 1")
+}
+
+func Test_DefaultTransportNotReplaced(t *testing.T) {
+	_, isStd := http.DefaultTransport.(*http.Transport)
+
+	assert.True(t, isStd, "importing the package must not hijack http.DefaultTransport")
+}
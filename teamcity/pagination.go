@@ -0,0 +1,391 @@
+package teamcity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultPageSize is the number of items iterators fetch per request when no page size is given
+const DefaultPageSize = 100
+
+// pageFunc fetches the page at path, keeping its items, and returns how many items it holds and the href of the next page
+type pageFunc func(ctx context.Context, helper *restHelper, path string) (int, string, error)
+
+// pager walks the pages of a list endpoint, fetching each page only once the items of the previous one were visited.
+// Iterators embed it and add a typed Item method.
+type pager struct {
+	ctx    context.Context
+	helper *restHelper
+	fetch  pageFunc
+
+	// path is the page to fetch next, empty once the last page was fetched
+	path string
+
+	index, size int
+	seen, limit int
+	err         error
+}
+
+func newPager(ctx context.Context, helper *restHelper, path string, limit int, fetch pageFunc) pager {
+	return pager{ctx: ctx, helper: helper, fetch: fetch, path: path, index: -1, limit: limit}
+}
+
+// Next advances to the next item, fetching the next page when needed. It returns false once all the items were visited
+// or when fetching a page failed, see Err.
+func (p *pager) Next() bool {
+	if p.err != nil || (p.limit > 0 && p.seen >= p.limit) {
+		return false
+	}
+
+	p.index++
+	for p.index >= p.size {
+		if p.path == "" {
+			return false
+		}
+		size, next, err := p.fetch(p.ctx, p.helper, p.path)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.index, p.size = 0, size
+
+		current := p.path
+		p.path = ""
+		if next != "" {
+			if p.path, err = p.helper.resolveHref(next); err != nil {
+				p.err = err
+				return false
+			}
+			if p.path == current {
+				p.path = ""
+			}
+		}
+	}
+	p.seen++
+	return true
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *pager) Err() error {
+	return p.err
+}
+
+// resolveHref turns an href returned by the server, such as "/app/rest/builds?locator=count:100,start:100",
+// into the URL of the same resource below the REST root the helper sends requests to, keeping the authentication prefix
+func (r *restHelper) resolveHref(href string) (string, error) {
+	const root = "/app/rest/"
+	req, err := r.sling.New().Request()
+	if err != nil {
+		return "", err
+	}
+	i := strings.Index(req.URL.Path, root)
+	j := strings.Index(href, root)
+	if i < 0 || j < 0 {
+		return "", fmt.Errorf("unexpected href %q", href)
+	}
+
+	base := url.URL{Scheme: req.URL.Scheme, User: req.URL.User, Host: req.URL.Host, Path: req.URL.Path[:i+len(root)]}
+	return base.String() + href[j+len(root):], nil
+}
+
+// pagedLocator adds the page size to an escaped locator
+func pagedLocator(locator Locator, pageSize int) Locator {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	count := NewLocatorBuilder().Count(pageSize).Locator()
+	if locator == "" {
+		return count
+	}
+	return locator + Locator(url.QueryEscape(",")) + count
+}
+
+func decodePage(ctx context.Context, helper *restHelper, path string, out interface{}, resourceDescription string) error {
+	return helper.getCustom(ctx, path, out, resourceDescription, json.Unmarshal)
+}
+
+// BuildIterator iterates over builds, fetching them page by page:
+//
+//	it := client.Builds.Iterate(filter, 100)
+//	for it.Next() {
+//		build := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type BuildIterator struct {
+	pager
+	items []*Build
+}
+
+func newBuildIterator(ctx context.Context, helper *restHelper, path string, limit int, resourceDescription string) *BuildIterator {
+	it := &BuildIterator{}
+	it.pager = newPager(ctx, helper, path, limit, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page Builds
+		if err := decodePage(ctx, helper, path, &page, resourceDescription); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current build. It must only be called after Next returned true.
+func (it *BuildIterator) Item() *Build {
+	return it.items[it.index]
+}
+
+// AgentIterator iterates over agents, fetching them page by page. See BuildIterator for an example.
+type AgentIterator struct {
+	pager
+	items []*AgentReference
+}
+
+func newAgentIterator(ctx context.Context, helper *restHelper, path string, limit int) *AgentIterator {
+	it := &AgentIterator{}
+	it.pager = newPager(ctx, helper, path, limit, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page AgentReferences
+		if err := decodePage(ctx, helper, path, &page, "agents"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current agent. It must only be called after Next returned true.
+func (it *AgentIterator) Item() *AgentReference {
+	return it.items[it.index]
+}
+
+// AgentPoolIterator iterates over agent pools, fetching them page by page. See BuildIterator for an example.
+type AgentPoolIterator struct {
+	pager
+	items []AgentPoolReference
+}
+
+func newAgentPoolIterator(ctx context.Context, helper *restHelper, path string) *AgentPoolIterator {
+	it := &AgentPoolIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page ListAgentPools
+		if err := decodePage(ctx, helper, path, &page, "Agent Pools"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.AgentPools
+		return len(page.AgentPools), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current agent pool. It must only be called after Next returned true.
+func (it *AgentPoolIterator) Item() *AgentPoolReference {
+	return &it.items[it.index]
+}
+
+// UserIterator iterates over users, fetching them page by page. See BuildIterator for an example.
+type UserIterator struct {
+	pager
+	items []*User
+}
+
+func newUserIterator(ctx context.Context, helper *restHelper, path string) *UserIterator {
+	it := &UserIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page Users
+		if err := decodePage(ctx, helper, path, &page, "users"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current user. It must only be called after Next returned true.
+func (it *UserIterator) Item() *User {
+	return it.items[it.index]
+}
+
+// ProjectIterator iterates over project references, fetching them page by page. See BuildIterator for an example.
+type ProjectIterator struct {
+	pager
+	items []*ProjectReference
+}
+
+func newProjectIterator(ctx context.Context, helper *restHelper, path string) *ProjectIterator {
+	it := &ProjectIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page ProjectsReferences
+		if err := decodePage(ctx, helper, path, &page, "projects"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current project. It must only be called after Next returned true.
+func (it *ProjectIterator) Item() *ProjectReference {
+	return it.items[it.index]
+}
+
+// BuildTypeIterator iterates over build configuration and template references, fetching them page by page. See BuildIterator for an example.
+type BuildTypeIterator struct {
+	pager
+	items []*BuildTypeReference
+}
+
+func newBuildTypeIterator(ctx context.Context, helper *restHelper, path string) *BuildTypeIterator {
+	it := &BuildTypeIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page BuildTypeReferences
+		if err := decodePage(ctx, helper, path, &page, "build types"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current build type. It must only be called after Next returned true.
+func (it *BuildTypeIterator) Item() *BuildTypeReference {
+	return it.items[it.index]
+}
+
+// VcsRootIterator iterates over VCS root references, fetching them page by page. See BuildIterator for an example.
+type VcsRootIterator struct {
+	pager
+	items []*VcsRootReference
+}
+
+func newVcsRootIterator(ctx context.Context, helper *restHelper, path string) *VcsRootIterator {
+	it := &VcsRootIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page VcsRootReferences
+		if err := decodePage(ctx, helper, path, &page, "vcs roots"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current VCS root. It must only be called after Next returned true.
+func (it *VcsRootIterator) Item() *VcsRootReference {
+	return it.items[it.index]
+}
+
+// GroupIterator iterates over groups, fetching them page by page. See BuildIterator for an example.
+type GroupIterator struct {
+	pager
+	items []*Group
+}
+
+func newGroupIterator(ctx context.Context, helper *restHelper, path string) *GroupIterator {
+	it := &GroupIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page Groups
+		if err := decodePage(ctx, helper, path, &page, "groups"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current group. It must only be called after Next returned true.
+func (it *GroupIterator) Item() *Group {
+	return it.items[it.index]
+}
+
+// ProjectFeatureIterator iterates over the features of a project, fetching them page by page. See BuildIterator for an example.
+type ProjectFeatureIterator struct {
+	pager
+	items []ProjectFeature
+}
+
+func newProjectFeatureIterator(ctx context.Context, s *ProjectFeatureService, path string) *ProjectFeatureIterator {
+	it := &ProjectFeatureIterator{}
+	it.pager = newPager(ctx, s.restHelper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page projectFeatures
+		if err := decodePage(ctx, helper, path, &page, "projectFeature"); err != nil {
+			return 0, "", err
+		}
+		it.items = make([]ProjectFeature, len(page.Items))
+		for i, featureJSON := range page.Items {
+			feature, err := s.parseProjectFeatureJSONResponse(featureJSON)
+			if err != nil {
+				return 0, "", err
+			}
+			it.items[i] = feature
+		}
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current project feature. It must only be called after Next returned true.
+func (it *ProjectFeatureIterator) Item() ProjectFeature {
+	return it.items[it.index]
+}
+
+// AgentRequirementIterator iterates over the agent requirements of a build configuration, fetching them page by page.
+// See BuildIterator for an example.
+type AgentRequirementIterator struct {
+	pager
+	items []*AgentRequirement
+}
+
+func newAgentRequirementIterator(ctx context.Context, s *AgentRequirementService, path string) *AgentRequirementIterator {
+	it := &AgentRequirementIterator{}
+	it.pager = newPager(ctx, s.restHelper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page agentRequirementsJSON
+		if err := decodePage(ctx, helper, path, &page, "agent requirements"); err != nil {
+			return 0, "", err
+		}
+		for _, i := range page.Items {
+			i.BuildTypeID = s.BuildTypeID
+		}
+		it.items = page.Items
+		return len(page.Items), page.NextHref, nil
+	})
+	return it
+}
+
+// Item returns the current agent requirement. It must only be called after Next returned true.
+func (it *AgentRequirementIterator) Item() *AgentRequirement {
+	return it.items[it.index]
+}
+
+// RoleAssignmentIterator iterates over role assignments. They are fetched in a single request, as TeamCity does not page them.
+// See BuildIterator for an example.
+type RoleAssignmentIterator struct {
+	pager
+	items []RoleAssignmentReference
+}
+
+func newRoleAssignmentIterator(ctx context.Context, helper *restHelper, path string) *RoleAssignmentIterator {
+	it := &RoleAssignmentIterator{}
+	it.pager = newPager(ctx, helper, path, 0, func(ctx context.Context, helper *restHelper, path string) (int, string, error) {
+		var page roleAssignmentsJSON
+		if err := decodePage(ctx, helper, path, &page, "role assignments"); err != nil {
+			return 0, "", err
+		}
+		it.items = page.Items
+		return len(page.Items), "", nil
+	})
+	return it
+}
+
+// Item returns the current role assignment. It must only be called after Next returned true.
+func (it *RoleAssignmentIterator) Item() *RoleAssignmentReference {
+	return &it.items[it.index]
+}
@@ -0,0 +1,246 @@
+package teamcity
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPagingClient serves total builds in pages of the requested count, linking pages with nextHref like TeamCity
+func newPagingClient(t *testing.T, total int, requests *[]string) (*Client, func()) {
+	return newPagingClientFor(t, "build", `{"id":%d}`, "/app/rest/builds", total, requests)
+}
+
+// newPagingClientFor serves total items formatted with item under key, in pages of the requested count.
+// nextHref links to the following page of next.
+func newPagingClientFor(t *testing.T, key string, item string, next string, total int, requests *[]string) (*Client, func()) {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path+"?"+r.URL.Query().Get("locator"))
+		if r.URL.Query().Get("locator") == "count:3,start:6" && total < 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		count, start := 0, 0
+		for _, dim := range strings.Split(r.URL.Query().Get("locator"), ",") {
+			if strings.HasPrefix(dim, "count:") {
+				count, _ = strconv.Atoi(strings.TrimPrefix(dim, "count:"))
+			}
+			if strings.HasPrefix(dim, "start:") {
+				start, _ = strconv.Atoi(strings.TrimPrefix(dim, "start:"))
+			}
+		}
+		n := total
+		if n < 0 {
+			n = 100
+		}
+		var items []string
+		for id := start; id < start+count && id < n; id++ {
+			items = append(items, fmt.Sprintf(item, id))
+		}
+		nextHref := ""
+		if start+count < n {
+			nextHref = fmt.Sprintf(`,"nextHref":"%s?locator=count:%d,start:%d"`, next, count, start+count)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"count":%d%s,"%s":[%s]}`, len(items), nextHref, key, strings.Join(items, ","))
+	}))
+}
+
+func Test_IteratorFollowsNextHref(t *testing.T) {
+	var requests []string
+	client, done := newPagingClient(t, 7, &requests)
+	defer done()
+
+	var ids []int
+	it := client.Builds.Iterate(nil, 3)
+	for it.Next() {
+		ids = append(ids, it.Item().ID)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, ids)
+	assert.Equal(t, []string{
+		"/httpAuth/app/rest/builds/?count:3",
+		"/httpAuth/app/rest/builds?count:3,start:3",
+		"/httpAuth/app/rest/builds?count:3,start:6",
+	}, requests)
+}
+
+func Test_IteratorFetchesPagesLazily(t *testing.T) {
+	var requests []string
+	client, done := newPagingClient(t, 100, &requests)
+	defer done()
+
+	it := client.Builds.Iterate(&BuildFilter{BuildTypeID: "Release", Count: 4}, 3)
+	visited := 0
+	for it.Next() {
+		visited++
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, 4, visited)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "/httpAuth/app/rest/builds/?buildType:(id:Release),count:3", requests[0])
+}
+
+func Test_IteratorStopsOnError(t *testing.T) {
+	var requests []string
+	client, done := newPagingClient(t, -1, &requests)
+	defer done()
+
+	visited := 0
+	it := client.Builds.Iterate(nil, 3)
+	for it.Next() {
+		visited++
+	}
+
+	assert.Equal(t, 6, visited)
+	require.Error(t, it.Err())
+	assert.False(t, it.Next())
+}
+
+func Test_IteratorDefaultPageSize(t *testing.T) {
+	var requests []string
+	client, done := newPagingClient(t, 0, &requests)
+	defer done()
+
+	it := client.Agents.Iterate(nil, 0)
+
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+	assert.Equal(t, "/httpAuth/app/rest/agents/?count:100", requests[0])
+}
+
+func Test_AgentIteratorMatchesListForCountOnlyFilter(t *testing.T) {
+	var requests []string
+	client, done := newPagingClient(t, 0, &requests)
+	defer done()
+
+	_, err := client.Agents.List(&AgentFilter{Count: 5})
+	require.NoError(t, err)
+	it := client.Agents.Iterate(&AgentFilter{Count: 5}, 2)
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+
+	assert.Equal(t, []string{
+		"/httpAuth/app/rest/agents/?count:5",
+		"/httpAuth/app/rest/agents/?count:2",
+	}, requests)
+}
+
+func Test_VcsRootIteratorRequestsReferenceFields(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"count":1,"vcs-root":[{"id":"V0","name":"Repository","project":{"id":"P"}}]}`, &requests))
+	defer done()
+
+	it := client.VcsRoots.Iterate(2)
+	require.True(t, it.Next())
+	assert.Equal(t, "Repository", it.Item().Name)
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/httpAuth/app/rest/vcs-roots/?fields="+url.QueryEscape("count,nextHref,vcs-root(id,name,href,project(id,name))")+"&locator=count%3A2", requests[0].URI)
+}
+
+func Test_ResourceIterators(t *testing.T) {
+	cases := []struct {
+		key, item, next string
+		first           string
+		collect         func(*Client) ([]string, error)
+	}{
+		{"project", `{"id":"P%d"}`, "/app/rest/projects", "/httpAuth/app/rest/projects/?count:2", func(c *Client) ([]string, error) {
+			var ids []string
+			it := c.Projects.Iterate(2)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+			}
+			return ids, it.Err()
+		}},
+		{"buildType", `{"id":"B%d"}`, "/app/rest/buildTypes", "/httpAuth/app/rest/buildTypes/?project:(id:P),templateFlag:any,count:2", func(c *Client) ([]string, error) {
+			var ids []string
+			it := c.BuildTypes.Iterate(&BuildTypeFilter{ProjectID: "P"}, 2)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+			}
+			return ids, it.Err()
+		}},
+		{"vcs-root", `{"id":"V%d"}`, "/app/rest/vcs-roots", "/httpAuth/app/rest/vcs-roots/?count:2", func(c *Client) ([]string, error) {
+			var ids []string
+			it := c.VcsRoots.Iterate(2)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+			}
+			return ids, it.Err()
+		}},
+		{"group", `{"key":"G%d"}`, "/app/rest/userGroups", "/httpAuth/app/rest/userGroups/?count:2", func(c *Client) ([]string, error) {
+			var ids []string
+			it := c.Groups.Iterate(2)
+			for it.Next() {
+				ids = append(ids, it.Item().Key)
+			}
+			return ids, it.Err()
+		}},
+		{"projectFeature", `{"id":"F%d","type":"versionedSettings","properties":{"property":[]}}`, "/app/rest/projects/P/projectFeatures", "/httpAuth/app/rest/projects/P/projectFeatures?count:2", func(c *Client) ([]string, error) {
+			var ids []string
+			it := c.ProjectFeatureService("P").Iterate(2)
+			for it.Next() {
+				ids = append(ids, it.Item().ID())
+			}
+			return ids, it.Err()
+		}},
+		{"agent-requirement", `{"id":"R%d"}`, "/app/rest/buildTypes/B/agent-requirements", "/httpAuth/app/rest/buildTypes/B/agent-requirements/?count:2", func(c *Client) ([]string, error) {
+			var ids []string
+			it := c.AgentRequirementService("B").Iterate(2)
+			for it.Next() {
+				assert.Equal(t, "B", it.Item().BuildTypeID)
+				ids = append(ids, it.Item().ID)
+			}
+			return ids, it.Err()
+		}},
+	}
+	for _, c := range cases {
+		var requests []string
+		client, done := newPagingClientFor(t, c.key, c.item, c.next, 3, &requests)
+		ids, err := c.collect(client)
+		done()
+
+		require.NoError(t, err, c.key)
+		assert.Len(t, ids, 3, c.key)
+		assert.Equal(t, []string{c.first, "/httpAuth" + c.next + "?count:2,start:2"}, requests, c.key)
+	}
+}
+
+func Test_ResolveHrefKeepsAuthenticationPrefix(t *testing.T) {
+	client, err := NewClientWithAddress(GuestAuth(), "http://teamcity:8111/ci", http.DefaultClient)
+	require.NoError(t, err)
+
+	actual, err := client.Builds.restHelper.resolveHref("/app/rest/builds?locator=count:100,start:100")
+
+	require.NoError(t, err)
+	assert.Equal(t, "http://teamcity:8111/ci/guestAuth/app/rest/builds?locator=count:100,start:100", actual)
+}
+
+func Test_RoleAssignmentIteratorFetchesAllRolesAtOnce(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"role":[{"roleId":"PROJECT_VIEWER","scope":"g"},{"roleId":"PROJECT_DEVELOPER","scope":"p:P"}]}`, &requests))
+	defer done()
+
+	var ids []string
+	it := client.RoleAssignments.IterateForGroup(&Group{Key: "DEVS"})
+	for it.Next() {
+		ids = append(ids, it.Item().RoleID)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"PROJECT_VIEWER", "PROJECT_DEVELOPER"}, ids)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/httpAuth/app/rest/userGroups/DEVS/roles", requests[0].URI)
+}
@@ -0,0 +1,274 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dghubble/sling"
+)
+
+const (
+	// AllUsersGroupKey is the key of the built-in group every user belongs to
+	AllUsersGroupKey = "ALL_USERS_GROUP"
+
+	// RootProjectID is the id of the project every other project descends from
+	RootProjectID = "_Root"
+
+	// GlobalScope is the role assignment scope covering the whole server
+	GlobalScope = "g"
+)
+
+// PermissionGrant is a role assignment that grants a permission, explaining why it is allowed
+type PermissionGrant struct {
+	// RoleID is the assigned role
+	RoleID string
+
+	// ViaRoleID is the role holding the permission, when it differs from RoleID because RoleID includes it
+	ViaRoleID string
+
+	// Scope is where the role is assigned: GlobalScope or "p:<project id>" for the checked project or one of its parents.
+	// TeamCity writes project scopes with the internal id of the project, such as "p:project12", but external ids are also matched.
+	Scope string
+
+	// GroupPath is empty when the role is assigned directly, otherwise the groups the role is inherited through,
+	// starting with a group of the user and ending with the group the role is assigned to
+	GroupPath []string
+}
+
+func (g *PermissionGrant) String() string {
+	role := g.RoleID
+	if g.ViaRoleID != "" {
+		role = fmt.Sprintf("%s (via included role %s)", g.RoleID, g.ViaRoleID)
+	}
+	if len(g.GroupPath) == 0 {
+		return fmt.Sprintf("role %s assigned directly with scope %s", role, g.Scope)
+	}
+	return fmt.Sprintf("role %s assigned to group %s with scope %s", role, strings.Join(g.GroupPath, " -> "), g.Scope)
+}
+
+// PermissionCheck is the answer to whether a user or group holds a permission on a project
+type PermissionCheck struct {
+	Permission string
+	ProjectID  string
+
+	// Allowed is true when at least one grant was found
+	Allowed bool
+
+	// Grants are all the role assignments granting the permission
+	Grants []*PermissionGrant
+}
+
+// PermissionService computes effective permissions from role assignments, following parent groups, parent projects and included roles
+type PermissionService struct {
+	httpClient    *http.Client
+	userHelper    *restHelper
+	groupHelper   *restHelper
+	projectHelper *restHelper
+	roles         *RoleService
+}
+
+func newPermissionService(base *sling.Sling, httpClient *http.Client, roles *RoleService) *PermissionService {
+	return &PermissionService{
+		httpClient:    httpClient,
+		userHelper:    newRestHelperWithSling(httpClient, base.New().Path("users/")),
+		groupHelper:   newRestHelperWithSling(httpClient, base.New().Path("userGroups/")),
+		projectHelper: newRestHelperWithSling(httpClient, base.New().Path("projects/")),
+		roles:         roles,
+	}
+}
+
+type permissionPrincipal struct {
+	Key          string              `json:"key,omitempty"`
+	Roles        roleAssignmentsJSON `json:"roles"`
+	Groups       Groups              `json:"groups"`
+	ParentGroups Groups              `json:"parent-groups"`
+}
+
+// Can reports whether the user with given username holds permission on the project with given id. Use RootProjectID for global permissions.
+func (s *PermissionService) Can(username string, permission string, projectID string) (bool, error) {
+	return s.CanWithContext(context.Background(), username, permission, projectID)
+}
+
+// CanWithContext reports whether the user with given username holds permission on the project with given id, bound to ctx
+func (s *PermissionService) CanWithContext(ctx context.Context, username string, permission string, projectID string) (bool, error) {
+	check, err := s.CheckUserWithContext(ctx, username, permission, projectID)
+	if err != nil {
+		return false, err
+	}
+	return check.Allowed, nil
+}
+
+// CheckUser reports whether the user with given username holds permission on the project with given id, and which role assignments grant it.
+// Roles assigned to the user, to its groups and their parent groups, and to the All Users group are considered.
+func (s *PermissionService) CheckUser(username string, permission string, projectID string) (*PermissionCheck, error) {
+	return s.CheckUserWithContext(context.Background(), username, permission, projectID)
+}
+
+// CheckUserWithContext reports whether the user holds permission on the project and which role assignments grant it, bound to ctx
+func (s *PermissionService) CheckUserWithContext(ctx context.Context, username string, permission string, projectID string) (*PermissionCheck, error) {
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+
+	var user permissionPrincipal
+	err := s.userHelper.get(ctx, LocatorUsername(username).String()+"?fields="+url.QueryEscape("roles(role(roleId,scope)),groups(group(key))"), &user, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []string{AllUsersGroupKey}
+	for _, g := range user.Groups.Items {
+		groups = append(groups, g.Key)
+	}
+	return s.check(ctx, user.Roles.Items, groups, permission, projectID)
+}
+
+// CheckGroup reports whether members of the group with given key hold permission on the project with given id, through the roles
+// of the group and its parent groups, and which role assignments grant it
+func (s *PermissionService) CheckGroup(groupKey string, permission string, projectID string) (*PermissionCheck, error) {
+	return s.CheckGroupWithContext(context.Background(), groupKey, permission, projectID)
+}
+
+// CheckGroupWithContext reports whether members of the group hold permission on the project, bound to ctx
+func (s *PermissionService) CheckGroupWithContext(ctx context.Context, groupKey string, permission string, projectID string) (*PermissionCheck, error) {
+	if groupKey == "" {
+		return nil, errors.New("groupKey is required")
+	}
+	return s.check(ctx, nil, []string{groupKey}, permission, projectID)
+}
+
+func (s *PermissionService) check(ctx context.Context, direct []RoleAssignmentReference, groups []string, permission string, projectID string) (*PermissionCheck, error) {
+	if permission == "" {
+		return nil, errors.New("permission is required")
+	}
+	if projectID == "" {
+		return nil, errors.New("projectID is required")
+	}
+
+	scopes, err := s.projectScopes(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.rolesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PermissionCheck{Permission: permission, ProjectID: projectID}
+	addGrants := func(assignments []RoleAssignmentReference, path []string) {
+		for _, a := range assignments {
+			via, global, ok := roleGrants(roles, a.RoleID, permission, map[string]bool{})
+			// Global permissions only come from roles assigned server-wide
+			if !ok || !scopes[a.Scope] || (global && a.Scope != GlobalScope) {
+				continue
+			}
+			grant := &PermissionGrant{RoleID: a.RoleID, Scope: a.Scope, GroupPath: path}
+			if via != a.RoleID {
+				grant.ViaRoleID = via
+			}
+			out.Grants = append(out.Grants, grant)
+		}
+	}
+
+	addGrants(direct, nil)
+
+	// Walk up the group hierarchy breadth first, remembering how each group was reached
+	paths := make(map[string][]string)
+	queue := make([]string, 0, len(groups))
+	for _, key := range groups {
+		if _, seen := paths[key]; !seen {
+			paths[key] = []string{key}
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		var group permissionPrincipal
+		err := s.groupHelper.get(ctx, LocatorKey(key).String()+"?fields="+url.QueryEscape("key,roles(role(roleId,scope)),parent-groups(group(key))"), &group, "group")
+		if err != nil {
+			return nil, err
+		}
+		addGrants(group.Roles.Items, paths[key])
+
+		for _, parent := range group.ParentGroups.Items {
+			if _, seen := paths[parent.Key]; seen {
+				continue
+			}
+			paths[parent.Key] = append(append([]string{}, paths[key]...), parent.Key)
+			queue = append(queue, parent.Key)
+		}
+	}
+
+	out.Allowed = len(out.Grants) > 0
+	return out, nil
+}
+
+// projectScopes returns the role scopes that apply to the project: the global scope, the project's and its parents',
+// each under both their external and internal id
+func (s *PermissionService) projectScopes(ctx context.Context, projectID string) (map[string]bool, error) {
+	scopes := map[string]bool{GlobalScope: true}
+	for id := projectID; id != ""; {
+		if scopes["p:"+id] {
+			break
+		}
+		scopes["p:"+id] = true
+		if id == RootProjectID {
+			break
+		}
+
+		var project struct {
+			InternalID      string `json:"internalId,omitempty"`
+			ParentProjectID string `json:"parentProjectId,omitempty"`
+		}
+		err := s.projectHelper.get(ctx, LocatorID(id).String()+"?fields=id,internalId,parentProjectId", &project, "project")
+		if err != nil {
+			return nil, err
+		}
+		if project.InternalID != "" {
+			scopes["p:"+project.InternalID] = true
+		}
+		id = project.ParentProjectID
+	}
+	return scopes, nil
+}
+
+func (s *PermissionService) rolesByID(ctx context.Context) (map[string]*Role, error) {
+	roles, err := s.roles.ListWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*Role, len(roles))
+	for _, r := range roles {
+		out[r.ID] = r
+	}
+	return out, nil
+}
+
+// roleGrants looks for permission in the role with given id and the roles it includes.
+// It returns the id of the role holding the permission and whether the permission is global.
+func roleGrants(roles map[string]*Role, id string, permission string, visited map[string]bool) (string, bool, bool) {
+	role, ok := roles[id]
+	if !ok || visited[id] {
+		return "", false, false
+	}
+	visited[id] = true
+
+	if p := role.permission(permission); p != nil {
+		return id, p.Global, true
+	}
+	if role.Included == nil {
+		return "", false, false
+	}
+	for _, included := range role.Included.Items {
+		if via, global, ok := roleGrants(roles, included.ID, permission, visited); ok {
+			return via, global, true
+		}
+	}
+	return "", false, false
+}
@@ -0,0 +1,134 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fakePermissionResponses = map[string]string{
+	"/httpAuth/app/rest/users/username:john":            `{"roles":{"role":[{"roleId":"PROJECT_DEVELOPER","scope":"p:project3"}]}}`,
+	"/httpAuth/app/rest/users/username:jane":            `{"roles":{"role":[{"roleId":"PROJECT_VIEWER","scope":"p:Team"}]},"groups":{"group":[{"key":"DEVS"}]}}`,
+	"/httpAuth/app/rest/userGroups/key:ALL_USERS_GROUP": `{"key":"ALL_USERS_GROUP"}`,
+	"/httpAuth/app/rest/userGroups/key:DEVS":            `{"key":"DEVS","parent-groups":{"group":[{"key":"ENGINEERING"}]}}`,
+	"/httpAuth/app/rest/userGroups/key:ENGINEERING":     `{"key":"ENGINEERING","roles":{"role":[{"roleId":"PROJECT_DEVELOPER","scope":"p:Team"},{"roleId":"PROJECT_ADMIN","scope":"p:Other"}]}}`,
+	"/httpAuth/app/rest/projects/id:Team_Service":       `{"id":"Team_Service","internalId":"project12","parentProjectId":"Team"}`,
+	"/httpAuth/app/rest/projects/id:Team":               `{"id":"Team","internalId":"project3","parentProjectId":"_Root"}`,
+	"/httpAuth/app/rest/roles/": `{"role":[
+		{"id":"PROJECT_VIEWER","permissions":{"permission":[{"id":"view_project"}]}},
+		{"id":"PROJECT_DEVELOPER","permissions":{"permission":[{"id":"run_build"},{"id":"create_user","global":true}]},"included":{"role":[{"id":"PROJECT_VIEWER"}]}},
+		{"id":"PROJECT_ADMIN","permissions":{"permission":[{"id":"edit_project"}]},"included":{"role":[{"id":"PROJECT_DEVELOPER"}]}}
+	]}`,
+}
+
+func serveFakePermissions(w http.ResponseWriter, r *http.Request) {
+	response, ok := fakePermissionResponses[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(response))
+}
+
+func Test_PermissionCheckUserThroughParentGroupAndProject(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	actual, err := client.Permissions.CheckUser("jane", "RUN_BUILD", "Team_Service")
+
+	require.NoError(t, err)
+	assert.True(t, actual.Allowed)
+	require.Len(t, actual.Grants, 1)
+	assert.Equal(t, "PROJECT_DEVELOPER", actual.Grants[0].RoleID)
+	assert.Equal(t, "p:Team", actual.Grants[0].Scope)
+	assert.Equal(t, []string{"DEVS", "ENGINEERING"}, actual.Grants[0].GroupPath)
+	assert.Equal(t, "role PROJECT_DEVELOPER assigned to group DEVS -> ENGINEERING with scope p:Team", actual.Grants[0].String())
+}
+
+func Test_PermissionCheckUserWithInternalIDScope(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	actual, err := client.Permissions.CheckUser("john", "run_build", "Team_Service")
+
+	require.NoError(t, err)
+	assert.True(t, actual.Allowed)
+	require.Len(t, actual.Grants, 1)
+	assert.Equal(t, "p:project3", actual.Grants[0].Scope)
+}
+
+func Test_PermissionCheckUserWithExternalIDScope(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	actual, err := client.Permissions.CheckUser("jane", "view_project", "Team")
+
+	require.NoError(t, err)
+	require.Len(t, actual.Grants, 2)
+	assert.Equal(t, "p:Team", actual.Grants[0].Scope)
+	assert.Equal(t, "p:Team", actual.Grants[1].Scope)
+}
+
+func Test_PermissionCheckUserThroughIncludedRoles(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	actual, err := client.Permissions.CheckUser("jane", "view_project", "Team_Service")
+
+	require.NoError(t, err)
+	require.Len(t, actual.Grants, 2)
+	assert.Equal(t, "role PROJECT_VIEWER assigned directly with scope p:Team", actual.Grants[0].String())
+	assert.Equal(t, "PROJECT_DEVELOPER", actual.Grants[1].RoleID)
+	assert.Equal(t, "PROJECT_VIEWER", actual.Grants[1].ViaRoleID)
+}
+
+func Test_PermissionCheckUserDenied(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	can, err := client.Permissions.Can("jane", "EDIT_PROJECT", "Team_Service")
+	require.NoError(t, err)
+	assert.False(t, can)
+
+	// Global permissions are only granted by roles assigned with the global scope
+	can, err = client.Permissions.Can("jane", "CREATE_USER", "Team")
+	require.NoError(t, err)
+	assert.False(t, can)
+}
+
+func Test_PermissionCanWithContextCancelled(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Permissions.CanWithContext(ctx, "jane", "EDIT_PROJECT", "Team_Service")
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func Test_PermissionCheckGroup(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	actual, err := client.Permissions.CheckGroup("DEVS", "run_build", "Team")
+
+	require.NoError(t, err)
+	assert.True(t, actual.Allowed)
+	assert.Equal(t, []string{"DEVS", "ENGINEERING"}, actual.Grants[0].GroupPath)
+}
+
+func Test_PermissionCheckUnknownUser(t *testing.T) {
+	client, done := newTestClient(t, http.HandlerFunc(serveFakePermissions))
+	defer done()
+
+	_, err := client.Permissions.CheckUser("nobody", "run_build", "Team")
+
+	assert.True(t, IsNotFound(err))
+}
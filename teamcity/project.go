@@ -4,8 +4,10 @@ package teamcity
 // Editing this file might prove futile when you re-run the swagger generate command
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/dghubble/sling"
 )
@@ -27,18 +29,20 @@ type Project struct {
 
 // ProjectsReferences contains subprojects, if exists
 type ProjectsReferences struct {
-	Count int                 `json:"count,omitempty" xml:"count"`
-	Items []*ProjectReference `json:"project,omitempty"`
+	Count    int                 `json:"count,omitempty" xml:"count"`
+	NextHref string              `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*ProjectReference `json:"project,omitempty"`
 }
 
 // ProjectReference contains basic information, usually enough to use as a type for relationships.
 // In addition to that, TeamCity does not return the full detailed representation when creating objects, thus the need for a reference.
 type ProjectReference struct {
-	ID          string `json:"id,omitempty" xml:"id"`
-	Name        string `json:"name,omitempty" xml:"name"`
-	Description string `json:"description,omitempty" xml:"description"`
-	Href        string `json:"href,omitempty" xml:"href"`
-	WebURL      string `json:"webUrl,omitempty" xml:"webUrl"`
+	ID              string `json:"id,omitempty" xml:"id"`
+	Name            string `json:"name,omitempty" xml:"name"`
+	Description     string `json:"description,omitempty" xml:"description"`
+	ParentProjectID string `json:"parentProjectId,omitempty" xml:"parentProjectId"`
+	Href            string `json:"href,omitempty" xml:"href"`
+	WebURL          string `json:"webUrl,omitempty" xml:"webUrl"`
 }
 
 // ProjectService has operations for handling projects
@@ -100,15 +104,20 @@ func newProjectService(base *sling.Sling, client *http.Client) *ProjectService {
 
 // Create creates a new project at root project level
 func (s *ProjectService) Create(project *Project) (*Project, error) {
+	return s.CreateWithContext(context.Background(), project)
+}
+
+// CreateWithContext creates a new project at root project level, bound to ctx
+func (s *ProjectService) CreateWithContext(ctx context.Context, project *Project) (*Project, error) {
 	var created ProjectReference
-	err := s.restHelper.post("", project, &created, "project")
+	err := s.restHelper.post(ctx, "", project, &created, "project")
 	if err != nil {
 		return nil, err
 	}
 
 	//initial creation does not persist "description" or parameters, so in order to be consistent with the constructor, call an update after
 	project.ID = created.ID
-	updated, err := s.updateProject(project, true)
+	updated, err := s.updateProject(ctx, project, true)
 
 	if err != nil {
 		return nil, err
@@ -119,9 +128,14 @@ func (s *ProjectService) Create(project *Project) (*Project, error) {
 
 // GetByID Retrieves a project resource by ID
 func (s *ProjectService) GetByID(id string) (*Project, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext Retrieves a project resource by ID, bound to ctx
+func (s *ProjectService) GetByIDWithContext(ctx context.Context, id string) (*Project, error) {
 	var out Project
 	locator := LocatorID(id).String()
-	err := s.restHelper.get(locator, &out, "project")
+	err := s.restHelper.get(ctx, locator, &out, "project")
 	if err != nil {
 		return nil, err
 	}
@@ -134,11 +148,62 @@ func (s *ProjectService) GetByID(id string) (*Project, error) {
 	return &out, err
 }
 
+// GetFields retrieves a project resource by ID, with only the selected fields
+func (s *ProjectService) GetFields(id string, fields Fields) (*Project, error) {
+	return s.GetFieldsWithContext(context.Background(), id, fields)
+}
+
+// GetFieldsWithContext retrieves a project resource by ID, with only the selected fields, bound to ctx
+func (s *ProjectService) GetFieldsWithContext(ctx context.Context, id string, fields Fields) (*Project, error) {
+	var out Project
+	err := s.restHelper.get(ctx, LocatorID(id).String()+fields.query(), &out, "project")
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Parameters != nil {
+		out.Parameters = out.Parameters.NonInherited()
+	}
+	return &out, nil
+}
+
+// ListReferences returns references to all projects, fetching only the fields of ProjectReference
+func (s *ProjectService) ListReferences() ([]*ProjectReference, error) {
+	return s.ListReferencesWithContext(context.Background())
+}
+
+// ListReferencesWithContext returns references to all projects, bound to ctx
+func (s *ProjectService) ListReferencesWithContext(ctx context.Context) ([]*ProjectReference, error) {
+	var out ProjectsReferences
+	err := s.restHelper.get(ctx, NewFields("count").Nested("project", ProjectReferenceFields).query(), &out, "projects")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate walks references to all projects, fetching pageSize projects per request. Use DefaultPageSize when pageSize is not positive.
+func (s *ProjectService) Iterate(pageSize int) *ProjectIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
+
+// IterateWithContext walks references to all projects page by page, bound to ctx
+func (s *ProjectService) IterateWithContext(ctx context.Context, pageSize int) *ProjectIterator {
+	fields := NewFields("count", "nextHref").Nested("project", ProjectReferenceFields)
+	return newProjectIterator(ctx, s.restHelper, "?locator="+pagedLocator("", pageSize).String()+"&fields="+url.QueryEscape(fields.String()))
+}
+
 //GetByName returns a project by its name. There are no duplicate names in projects for TeamCity
 func (s *ProjectService) GetByName(name string) (*Project, error) {
+	return s.GetByNameWithContext(context.Background(), name)
+}
+
+//GetByNameWithContext returns a project by its name, bound to ctx
+func (s *ProjectService) GetByNameWithContext(ctx context.Context, name string) (*Project, error) {
 	var out Project
 
-	err := s.restHelper.get(LocatorName(name).String(), &out, "project")
+	err := s.restHelper.get(ctx, LocatorName(name).String(), &out, "project")
 	if err != nil {
 		return nil, err
 	}
@@ -152,29 +217,39 @@ func (s *ProjectService) GetByName(name string) (*Project, error) {
 //TeamCity API does not support "PUT" on the whole project resource, so the only updateable field is "Description". Other field updates will be ignored.
 //This method also updates Settings and Parameters, but this is not an atomic operation. If an error occurs, it will be returned to caller what was updated or not.
 func (s *ProjectService) Update(project *Project) (*Project, error) {
-	return s.updateProject(project, false)
+	return s.UpdateWithContext(context.Background(), project)
+}
+
+//UpdateWithContext changes the resource in-place for this project, bound to ctx. See Update for the caveats.
+func (s *ProjectService) UpdateWithContext(ctx context.Context, project *Project) (*Project, error) {
+	return s.updateProject(ctx, project, false)
 }
 
 //Delete - Deletes a project
 func (s *ProjectService) Delete(id string) error {
-	err := s.restHelper.deleteByIDWithSling(s.sling.New(), id, "project")
+	return s.DeleteWithContext(context.Background(), id)
+}
+
+//DeleteWithContext - Deletes a project, bound to ctx
+func (s *ProjectService) DeleteWithContext(ctx context.Context, id string) error {
+	err := s.restHelper.deleteByIDWithSling(ctx, s.sling.New(), id, "project")
 	return err
 }
 
-func (s *ProjectService) updateProject(project *Project, isCreate bool) (*Project, error) {
-	_, err := s.restHelper.putTextPlain(project.ID+"/name", project.Name, "project name")
+func (s *ProjectService) updateProject(ctx context.Context, project *Project, isCreate bool) (*Project, error) {
+	_, err := s.restHelper.putTextPlain(ctx, project.ID+"/name", project.Name, "project name")
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = s.restHelper.putTextPlain(project.ID+"/description", project.Description, "project description")
+	_, err = s.restHelper.putTextPlain(ctx, project.ID+"/description", project.Description, "project description")
 	if err != nil {
 		return nil, err
 	}
 
 	//Update Parent
 	if !isCreate {
-		current, err := s.GetByID(project.ID)
+		current, err := s.GetByIDWithContext(ctx, project.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -183,7 +258,7 @@ func (s *ProjectService) updateProject(project *Project, isCreate bool) (*Projec
 		// For instance: "project" -> "project (1)"
 		if (project.ParentProjectID != "" || project.ParentProject != nil) && current.ParentProjectID != project.ParentProjectID {
 			var parent ProjectReference
-			err = s.restHelper.put(project.ID+"/parentProject", project.ParentProject, &parent, "parent project")
+			err = s.restHelper.put(ctx, project.ID+"/parentProject", project.ParentProject, &parent, "parent project")
 			if err != nil {
 				return nil, nil
 			}
@@ -193,12 +268,12 @@ func (s *ProjectService) updateProject(project *Project, isCreate bool) (*Projec
 	//Update Parameters
 	if project.Parameters.Count > 0 {
 		var parameters *Parameters
-		err = s.restHelper.put(project.ID+"/parameters", project.Parameters, &parameters, "project parameters")
+		err = s.restHelper.put(ctx, project.ID+"/parameters", project.Parameters, &parameters, "project parameters")
 		if err != nil {
 			return nil, err
 		}
 	}
-	out, err := s.GetByID(project.ID) //Refresh after update
+	out, err := s.GetByIDWithContext(ctx, project.ID) //Refresh after update
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -28,9 +29,10 @@ type projectFeatureJSON struct {
 }
 
 type projectFeatures struct {
-	Count int32                `json:"count,omitempty" xml:"count"`
-	Href  string               `json:"href,omitempty" xml:"href"`
-	Items []projectFeatureJSON `json:"projectFeature"`
+	Count    int32                `json:"count,omitempty" xml:"count"`
+	Href     string               `json:"href,omitempty" xml:"href"`
+	NextHref string               `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []projectFeatureJSON `json:"projectFeature"`
 }
 
 // ProjectFeatureService provides operations for managing project features.
@@ -48,6 +50,11 @@ func newProjectFeatureService(projectID string, c *http.Client, sling *sling.Sli
 
 // Create creates a new ProjectFeature under the current project.
 func (s *ProjectFeatureService) Create(feature ProjectFeature) (ProjectFeature, error) {
+	return s.CreateWithContext(context.Background(), feature)
+}
+
+// CreateWithContext creates a new ProjectFeature under the current project, bound to ctx.
+func (s *ProjectFeatureService) CreateWithContext(ctx context.Context, feature ProjectFeature) (ProjectFeature, error) {
 	if feature == nil {
 		return nil, fmt.Errorf("feature is nil")
 	}
@@ -62,7 +69,7 @@ func (s *ProjectFeatureService) Create(feature ProjectFeature) (ProjectFeature,
 	createdProjectFeature := &projectFeatureJSON{}
 
 	url := fmt.Sprintf("projects/%s/projectFeatures", s.ProjectID)
-	if err := s.restHelper.post(url, &requestBody, createdProjectFeature, "projectFeature"); err != nil {
+	if err := s.restHelper.post(ctx, url, &requestBody, createdProjectFeature, "projectFeature"); err != nil {
 		return nil, err
 	}
 
@@ -71,8 +78,13 @@ func (s *ProjectFeatureService) Create(feature ProjectFeature) (ProjectFeature,
 
 // Delete removes a single ProjectFeature for the current project by it's id.
 func (s *ProjectFeatureService) Delete(id string) error {
+	return s.DeleteWithContext(context.Background(), id)
+}
+
+// DeleteWithContext removes a single ProjectFeature for the current project by it's id, bound to ctx.
+func (s *ProjectFeatureService) DeleteWithContext(ctx context.Context, id string) error {
 	url := fmt.Sprintf("projects/%s/projectFeatures/%s", s.ProjectID, id)
-	if err := s.restHelper.delete(url, "projectFeature"); err != nil {
+	if err := s.restHelper.delete(ctx, url, "projectFeature"); err != nil {
 		return err
 	}
 
@@ -81,10 +93,15 @@ func (s *ProjectFeatureService) Delete(id string) error {
 
 // Get all project features for the current project.
 func (s *ProjectFeatureService) Get() ([]ProjectFeature, error) {
+	return s.GetWithContext(context.Background())
+}
+
+// GetWithContext gets all project features for the current project, bound to ctx.
+func (s *ProjectFeatureService) GetWithContext(ctx context.Context) ([]ProjectFeature, error) {
 	var out projectFeatures
 
 	url := fmt.Sprintf("projects/%s/projectFeatures", s.ProjectID)
-	if err := s.restHelper.get(url, &out, "projectFeature"); err != nil {
+	if err := s.restHelper.get(ctx, url, &out, "projectFeature"); err != nil {
 		return nil, err
 	}
 
@@ -101,13 +118,30 @@ func (s *ProjectFeatureService) Get() ([]ProjectFeature, error) {
 	return result, nil
 }
 
+// Iterate walks all project features for the current project, fetching pageSize features per request.
+// Use DefaultPageSize when pageSize is not positive.
+func (s *ProjectFeatureService) Iterate(pageSize int) *ProjectFeatureIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
+
+// IterateWithContext walks all project features for the current project page by page, bound to ctx.
+func (s *ProjectFeatureService) IterateWithContext(ctx context.Context, pageSize int) *ProjectFeatureIterator {
+	path := fmt.Sprintf("projects/%s/projectFeatures?locator=%s", s.ProjectID, pagedLocator("", pageSize))
+	return newProjectFeatureIterator(ctx, s, path)
+}
+
 // GetByID returns a single ProjectFeature for the current project by it's id.
 func (s *ProjectFeatureService) GetByID(id string) (ProjectFeature, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext returns a single ProjectFeature for the current project by it's id, bound to ctx.
+func (s *ProjectFeatureService) GetByIDWithContext(ctx context.Context, id string) (ProjectFeature, error) {
 	var out projectFeatureJSON
 
 	loc := LocatorID(id)
 	url := fmt.Sprintf("projects/%s/projectFeatures/%s", s.ProjectID, loc)
-	if err := s.restHelper.get(url, &out, "projectFeature"); err != nil {
+	if err := s.restHelper.get(ctx, url, &out, "projectFeature"); err != nil {
 		return nil, err
 	}
 
@@ -116,11 +150,16 @@ func (s *ProjectFeatureService) GetByID(id string) (ProjectFeature, error) {
 
 // GetByType returns a single ProjectFeature for the current project by it's typw.
 func (s *ProjectFeatureService) GetByType(id string) (ProjectFeature, error) {
+	return s.GetByTypeWithContext(context.Background(), id)
+}
+
+// GetByTypeWithContext returns a single ProjectFeature for the current project by it's type, bound to ctx.
+func (s *ProjectFeatureService) GetByTypeWithContext(ctx context.Context, id string) (ProjectFeature, error) {
 	var out projectFeatureJSON
 
 	loc := LocatorType(id)
 	url := fmt.Sprintf("projects/%s/projectFeatures/%s", s.ProjectID, loc)
-	if err := s.restHelper.get(url, &out, "projectFeature"); err != nil {
+	if err := s.restHelper.get(ctx, url, &out, "projectFeature"); err != nil {
 		return nil, err
 	}
 
@@ -129,6 +168,11 @@ func (s *ProjectFeatureService) GetByType(id string) (ProjectFeature, error) {
 
 // Update updated an existing a ProjectFeature under the current project.
 func (s *ProjectFeatureService) Update(feature ProjectFeature) (ProjectFeature, error) {
+	return s.UpdateWithContext(context.Background(), feature)
+}
+
+// UpdateWithContext updates an existing ProjectFeature under the current project, bound to ctx.
+func (s *ProjectFeatureService) UpdateWithContext(ctx context.Context, feature ProjectFeature) (ProjectFeature, error) {
 	if feature == nil {
 		return nil, fmt.Errorf("feature is nil")
 	}
@@ -143,7 +187,7 @@ func (s *ProjectFeatureService) Update(feature ProjectFeature) (ProjectFeature,
 	updatedProjectFeature := &projectFeatureJSON{}
 
 	url := fmt.Sprintf("projects/%s/projectFeatures/%s", s.ProjectID, feature.ID())
-	if err := s.restHelper.put(url, &requestBody, updatedProjectFeature, "projectFeature"); err != nil {
+	if err := s.restHelper.put(ctx, url, &requestBody, updatedProjectFeature, "projectFeature"); err != nil {
 		return nil, err
 	}
 
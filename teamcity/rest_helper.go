@@ -1,11 +1,10 @@
 package teamcity
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"reflect"
 	"strings"
@@ -31,9 +30,8 @@ func newRestHelperWithSling(httpClient *http.Client, s *sling.Sling) *restHelper
 	}
 }
 
-func (r *restHelper) getCustom(path string, out interface{}, resourceDescription string, reader responseReadFunc) error {
-	request, _ := r.sling.New().Get(path).Request()
-	response, err := r.httpClient.Do(request)
+func (r *restHelper) getCustom(ctx context.Context, path string, out interface{}, resourceDescription string, reader responseReadFunc) error {
+	response, err := r.do(ctx, r.sling.New().Get(path))
 	if err != nil {
 		return err
 	}
@@ -51,12 +49,11 @@ func (r *restHelper) getCustom(path string, out interface{}, resourceDescription
 		return nil
 	}
 
-	return r.handleRestError(bodyBytes, response.StatusCode, "GET", resourceDescription)
+	return r.handleRestError(bodyBytes, response, resourceDescription)
 }
 
-func (r *restHelper) get(path string, out interface{}, resourceDescription string) error {
-	request, _ := r.sling.New().Get(path).Request()
-	response, err := r.httpClient.Do(request)
+func (r *restHelper) get(ctx context.Context, path string, out interface{}, resourceDescription string) error {
+	response, err := r.do(ctx, r.sling.New().Get(path))
 	if err != nil {
 		return err
 	}
@@ -72,12 +69,31 @@ func (r *restHelper) get(path string, out interface{}, resourceDescription strin
 	if err != nil {
 		return err
 	}
-	return r.handleRestError(dt, response.StatusCode, "GET", resourceDescription)
+	return r.handleRestError(dt, response, resourceDescription)
 }
 
-func (r *restHelper) putCustom(path string, data interface{}, out interface{}, resourceDescription string, reader responseReadFunc) error {
-	request, _ := r.sling.New().Put(path).BodyJSON(data).Request()
-	response, err := r.httpClient.Do(request)
+// getStream performs the request described by s and hands the response over without buffering its body.
+// Any 2xx response is returned as is and must be closed by the caller.
+func (r *restHelper) getStream(ctx context.Context, s *sling.Sling, resourceDescription string) (*http.Response, error) {
+	response, err := r.do(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return response, nil
+	}
+
+	defer response.Body.Close()
+	dt, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return nil, r.handleRestError(dt, response, resourceDescription)
+}
+
+func (r *restHelper) putCustom(ctx context.Context, path string, data interface{}, out interface{}, resourceDescription string, reader responseReadFunc) error {
+	response, err := r.do(ctx, r.sling.New().Put(path).BodyJSON(data))
 	if err != nil {
 		return err
 	}
@@ -96,12 +112,11 @@ func (r *restHelper) putCustom(path string, data interface{}, out interface{}, r
 		return nil
 	}
 
-	return r.handleRestError(bodyBytes, response.StatusCode, "PUT", resourceDescription)
+	return r.handleRestError(bodyBytes, response, resourceDescription)
 }
 
-func (r *restHelper) postCustom(path string, data interface{}, out interface{}, resourceDescription string, reader responseReadFunc) error {
-	request, _ := r.sling.New().Post(path).BodyJSON(data).Request()
-	response, err := r.httpClient.Do(request)
+func (r *restHelper) postCustom(ctx context.Context, path string, data interface{}, out interface{}, resourceDescription string, reader responseReadFunc) error {
+	response, err := r.do(ctx, r.sling.New().Post(path).BodyJSON(data))
 	if err != nil {
 		return err
 	}
@@ -120,19 +135,13 @@ func (r *restHelper) postCustom(path string, data interface{}, out interface{},
 		return nil
 	}
 
-	return r.handleRestError(bodyBytes, response.StatusCode, "POST", resourceDescription)
+	return r.handleRestError(bodyBytes, response, resourceDescription)
 }
 
-func (r *restHelper) putTextPlain(path string, data string, resourceDescription string) (string, error) {
-	req, err := r.sling.New().Put(path).
+func (r *restHelper) putTextPlain(ctx context.Context, path string, data string, resourceDescription string) (string, error) {
+	resp, err := r.do(ctx, r.sling.New().Put(path).
 		BodyProvider(textPlainBodyProvider{payload: data}).
-		Add("Accept", "text/plain").
-		Request()
-
-	if err != nil {
-		return "", err
-	}
-	resp, err := r.httpClient.Do(req)
+		Add("Accept", "text/plain"))
 	if err != nil {
 		return "", err
 	}
@@ -143,16 +152,15 @@ func (r *restHelper) putTextPlain(path string, data string, resourceDescription
 		return "", err
 	}
 
-	if resp.StatusCode == 201 || resp.StatusCode == 200 {
+	if resp.StatusCode == 201 || resp.StatusCode == 200 || resp.StatusCode == 204 {
 		return string(bodyBytes), nil
 	}
 
-	return "", r.handleRestError(bodyBytes, resp.StatusCode, "PUT", resourceDescription)
+	return "", r.handleRestError(bodyBytes, resp, resourceDescription)
 }
 
-func (r *restHelper) post(path string, data interface{}, out interface{}, resourceDescription string) error {
-	request, _ := r.sling.New().Post(path).BodyJSON(data).Request()
-	response, err := r.httpClient.Do(request)
+func (r *restHelper) post(ctx context.Context, path string, data interface{}, out interface{}, resourceDescription string) error {
+	response, err := r.do(ctx, r.sling.New().Post(path).BodyJSON(data))
 
 	if err != nil {
 		return err
@@ -167,12 +175,11 @@ func (r *restHelper) post(path string, data interface{}, out interface{}, resour
 	if err != nil {
 		return err
 	}
-	return r.handleRestError(dt, response.StatusCode, "POST", resourceDescription)
+	return r.handleRestError(dt, response, resourceDescription)
 }
 
-func (r *restHelper) put(path string, data interface{}, out interface{}, resourceDescription string) error {
-	request, _ := r.sling.New().Put(path).BodyJSON(data).Request()
-	response, err := r.httpClient.Do(request)
+func (r *restHelper) put(ctx context.Context, path string, data interface{}, out interface{}, resourceDescription string) error {
+	response, err := r.do(ctx, r.sling.New().Put(path).BodyJSON(data))
 
 	if err != nil {
 		return err
@@ -187,16 +194,15 @@ func (r *restHelper) put(path string, data interface{}, out interface{}, resourc
 	if err != nil {
 		return err
 	}
-	return r.handleRestError(dt, response.StatusCode, "PUT", resourceDescription)
+	return r.handleRestError(dt, response, resourceDescription)
 }
 
-func (r *restHelper) delete(path string, resourceDescription string) error {
-	return r.deleteByIDWithSling(r.sling, path, resourceDescription)
+func (r *restHelper) delete(ctx context.Context, path string, resourceDescription string) error {
+	return r.deleteByIDWithSling(ctx, r.sling, path, resourceDescription)
 }
 
-func (r *restHelper) deleteByIDWithSling(sling *sling.Sling, resourceID string, resourceDescription string) error {
-	request, _ := sling.New().Delete(resourceID).Request()
-	response, err := r.httpClient.Do(request)
+func (r *restHelper) deleteByIDWithSling(ctx context.Context, sling *sling.Sling, resourceID string, resourceDescription string) error {
+	response, err := r.do(ctx, sling.New().Delete(resourceID))
 	if err != nil {
 		return err
 	}
@@ -211,14 +217,23 @@ func (r *restHelper) deleteByIDWithSling(sling *sling.Sling, resourceID string,
 		if err != nil {
 			return err
 		}
-		return r.handleRestError(dt, response.StatusCode, "DELETE", resourceDescription)
+		return r.handleRestError(dt, response, resourceDescription)
 	}
 
 	return nil
 }
 
-func (r *restHelper) handleRestError(dt []byte, status int, op string, res string) error {
-	return fmt.Errorf("Error '%d' when performing '%s' operation - %s: %s", status, op, res, string(dt))
+// do builds the request described by s, binds it to ctx and sends it with the helper's http client
+func (r *restHelper) do(ctx context.Context, s *sling.Sling) (*http.Response, error) {
+	request, err := s.Request()
+	if err != nil {
+		return nil, err
+	}
+	return r.httpClient.Do(request.WithContext(ctx))
+}
+
+func (r *restHelper) handleRestError(dt []byte, response *http.Response, res string) error {
+	return newAPIError(response, dt, res)
 }
 
 func replaceValue(i, v interface{}) {
@@ -255,11 +270,3 @@ func (p textPlainBodyProvider) ContentType() string {
 func (p textPlainBodyProvider) Body() (io.Reader, error) {
 	return strings.NewReader(p.payload.(string)), nil
 }
-
-func debug(data []byte, err error) {
-	if err == nil {
-		fmt.Printf("%s\n\n", data)
-	} else {
-		log.Printf("[ERROR] %s\n\n", err)
-	}
-}
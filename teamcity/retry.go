@@ -0,0 +1,154 @@
+package teamcity
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	//DefaultRetryMaxAttempts is the number of attempts made by ExponentialBackoff when MaxAttempts is not set
+	DefaultRetryMaxAttempts = 5
+	//DefaultRetryBaseDelay is the delay before the first retry made by ExponentialBackoff when BaseDelay is not set
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	//DefaultRetryMaxDelay is the upper bound for a single backoff of ExponentialBackoff when MaxDelay is not set
+	DefaultRetryMaxDelay = 30 * time.Second
+)
+
+// RetryPolicy decides whether a request sent by the client should be attempted again.
+// Retry is called after every attempt, starting at 1, with the response or the transport error of that attempt.
+// It returns how long to wait before the next attempt and whether there should be one at all.
+type RetryPolicy interface {
+	Retry(req *http.Request, attempt int, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff is the default RetryPolicy. It retries idempotent requests (GET, HEAD, PUT, DELETE, OPTIONS)
+// with an exponentially growing, jittered delay, and honors the Retry-After header sent by the server.
+// POST requests are only retried when RetryPOST is set.
+type ExponentialBackoff struct {
+	// MaxAttempts is the total number of attempts, including the first one. Defaults to DefaultRetryMaxAttempts.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubled on every subsequent one. Defaults to DefaultRetryBaseDelay.
+	BaseDelay time.Duration
+
+	// MaxDelay caps a single computed backoff. Defaults to DefaultRetryMaxDelay.
+	MaxDelay time.Duration
+
+	// DisableJitter turns off the randomization of the computed backoff.
+	DisableJitter bool
+
+	// RetryPOST opts POST requests in to be retried. Only enable if the operations being performed are safe to repeat.
+	RetryPOST bool
+
+	// Retryable reports whether a response or transport error is transient. Defaults to IsRetryable.
+	Retryable func(resp *http.Response, err error) bool
+}
+
+// NewExponentialBackoff returns an ExponentialBackoff with default settings
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// IsRetryable reports whether the outcome of a request is worth retrying: transport errors,
+// "429 Too Many Requests" and the 502, 503 and 504 statuses TeamCity answers with while restarting.
+func IsRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Retry implements RetryPolicy
+func (b *ExponentialBackoff) Retry(req *http.Request, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if req.Context().Err() != nil {
+		return 0, false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	case http.MethodPost:
+		if !b.RetryPOST {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	maxAttempts := b.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultRetryMaxAttempts
+	}
+	if attempt >= maxAttempts {
+		return 0, false
+	}
+
+	retryable := b.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	if !retryable(resp, err) {
+		return 0, false
+	}
+
+	if wait, ok := retryAfter(resp); ok {
+		return wait, true
+	}
+	return b.backoff(attempt), true
+}
+
+func (b *ExponentialBackoff) backoff(attempt int) time.Duration {
+	base, max := b.BaseDelay, b.MaxDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultRetryMaxDelay
+	}
+
+	wait := base
+	for i := 1; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+
+	if b.DisableJitter {
+		return wait
+	}
+	// "Equal jitter": keep half of the backoff and randomize the other half
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses the Retry-After header of resp, which can either be a number of seconds or an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(v); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
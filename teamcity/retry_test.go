@@ -0,0 +1,138 @@
+package teamcity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetryTestServer(failures int, status int) (*httptest.Server, *int) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"Project1","name":"Project 1"}`))
+	}))
+	return server, &attempts
+}
+
+func fastBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func Test_RetryDisabledByDefault(t *testing.T) {
+	server, attempts := newRetryTestServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+
+	_, err := client.Projects.GetByID("Project1")
+
+	require.Error(t, err)
+	assert.Equal(t, 1, *attempts)
+}
+
+func Test_RetryPolicyRetriesTransientFailures(t *testing.T) {
+	server, attempts := newRetryTestServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	client.RetryPolicy = fastBackoff()
+
+	actual, err := client.Projects.GetByID("Project1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Project1", actual.ID)
+	assert.Equal(t, 3, *attempts)
+}
+
+func Test_RetryPolicyGivesUpAfterMaxAttempts(t *testing.T) {
+	server, attempts := newRetryTestServer(5, http.StatusServiceUnavailable)
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	client.RetryPolicy = fastBackoff()
+
+	_, err := client.Projects.GetByID("Project1")
+
+	require.Error(t, err)
+	assert.Equal(t, 3, *attempts)
+}
+
+func Test_RetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	server, attempts := newRetryTestServer(1, http.StatusNotFound)
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	client.RetryPolicy = fastBackoff()
+
+	_, err := client.Projects.GetByID("Project1")
+
+	require.Error(t, err)
+	assert.Equal(t, 1, *attempts)
+}
+
+func Test_RetryPolicyPOSTIsOptIn(t *testing.T) {
+	server, attempts := newRetryTestServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	client.RetryPolicy = fastBackoff()
+
+	_, err := client.Groups.Create(&Group{Key: "KEY", Name: "name"})
+	require.Error(t, err)
+	assert.Equal(t, 1, *attempts)
+
+	*attempts = 0
+	policy := fastBackoff()
+	policy.RetryPOST = true
+	client.RetryPolicy = policy
+
+	_, err = client.Groups.Create(&Group{Key: "KEY", Name: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, *attempts)
+}
+
+func Test_RetryTimeoutEnablesDefaultPolicy(t *testing.T) {
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), "http://localhost", http.DefaultClient)
+	assert.Nil(t, client.retryPolicy())
+
+	client.RetryTimeout = time.Minute
+	assert.IsType(t, &ExponentialBackoff{}, client.retryPolicy())
+}
+
+func Test_RetryTimeoutStopsRetrying(t *testing.T) {
+	server, attempts := newRetryTestServer(5, http.StatusServiceUnavailable)
+	defer server.Close()
+	client, _ := NewClientWithAddress(BasicAuth("admin", "admin"), server.URL, http.DefaultClient)
+	client.RetryPolicy = &ExponentialBackoff{MaxAttempts: 5, BaseDelay: time.Second, DisableJitter: true}
+	client.RetryTimeout = 100 * time.Millisecond
+
+	_, err := client.Projects.GetByID("Project1")
+
+	require.Error(t, err)
+	assert.Equal(t, 1, *attempts)
+}
+
+func Test_ExponentialBackoffHonorsRetryAfter(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "7")
+
+	wait, retry := NewExponentialBackoff().Retry(req, 1, resp, nil)
+
+	assert.True(t, retry)
+	assert.Equal(t, 7*time.Second, wait)
+}
+
+func Test_ExponentialBackoffGrowsUpToMaxDelay(t *testing.T) {
+	sut := &ExponentialBackoff{BaseDelay: time.Second, MaxDelay: 5 * time.Second, DisableJitter: true}
+
+	assert.Equal(t, time.Second, sut.backoff(1))
+	assert.Equal(t, 2*time.Second, sut.backoff(2))
+	assert.Equal(t, 4*time.Second, sut.backoff(3))
+	assert.Equal(t, 5*time.Second, sut.backoff(4))
+}
@@ -0,0 +1,109 @@
+package teamcity
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/dghubble/sling"
+)
+
+// Role is a named set of permissions that can be assigned to users and groups, such as PROJECT_ADMIN
+type Role struct {
+	ID          string       `json:"id,omitempty" xml:"id"`
+	Name        string       `json:"name,omitempty" xml:"name"`
+	Href        string       `json:"href,omitempty" xml:"href"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+
+	// Included are the roles whose permissions this role also grants
+	Included *Roles `json:"included,omitempty"`
+}
+
+// Roles is a collection of Role
+type Roles struct {
+	Count int     `json:"count,omitempty" xml:"count"`
+	Items []*Role `json:"role"`
+}
+
+// Permission is an operation a role allows, such as RUN_BUILD or EDIT_PROJECT
+type Permission struct {
+	ID   string `json:"id,omitempty" xml:"id"`
+	Name string `json:"name,omitempty" xml:"name"`
+
+	// Global permissions apply to the whole server instead of the project a role is assigned on
+	Global bool `json:"global,omitempty" xml:"global"`
+}
+
+// Permissions is a collection of Permission
+type Permissions struct {
+	Count int           `json:"count,omitempty" xml:"count"`
+	Items []*Permission `json:"permission"`
+}
+
+// HasPermission reports whether the role grants the permission with given id, without following included roles.
+// Ids are compared ignoring case, so both "RUN_BUILD" and "run_build" match.
+func (r *Role) HasPermission(id string) bool {
+	return r.permission(id) != nil
+}
+
+func (r *Role) permission(id string) *Permission {
+	if r.Permissions == nil {
+		return nil
+	}
+	for _, p := range r.Permissions.Items {
+		if strings.EqualFold(p.ID, id) {
+			return p
+		}
+	}
+	return nil
+}
+
+const roleFields = "id,name,href,permissions(permission(id,name,global)),included(role(id,name,href))"
+
+// RoleService has operations for reading the roles defined on the server
+type RoleService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+}
+
+func newRoleService(base *sling.Sling, httpClient *http.Client) *RoleService {
+	sling := base.New().Path("roles/")
+	return &RoleService{
+		sling:      sling,
+		httpClient: httpClient,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+	}
+}
+
+// List returns all the roles defined on the server, with their permissions
+func (s *RoleService) List() ([]*Role, error) {
+	return s.ListWithContext(context.Background())
+}
+
+// ListWithContext returns all the roles defined on the server, bound to ctx
+func (s *RoleService) ListWithContext(ctx context.Context) ([]*Role, error) {
+	var out Roles
+	err := s.restHelper.get(ctx, "?fields=role("+roleFields+")", &out, "roles")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// GetByID returns a role and its permissions by its id, such as PROJECT_ADMIN
+func (s *RoleService) GetByID(id string) (*Role, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext returns a role and its permissions by its id, bound to ctx
+func (s *RoleService) GetByIDWithContext(ctx context.Context, id string) (*Role, error) {
+	var out Role
+	err := s.restHelper.get(ctx, LocatorID(id).String()+"?fields="+roleFields, &out, "role")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
@@ -1,6 +1,7 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -14,6 +15,13 @@ type GroupRoleAssignment struct {
 	Scope    string //`json:"scope,omitempty" xml:"scope"`
 }
 
+// UserRoleAssignment is the model for role assignment for users in TeamCity
+type UserRoleAssignment struct {
+	Username string
+	RoleID   string
+	Scope    string
+}
+
 // RoleAssignmentReference represents a response of a request to assign role to a group or a user
 type RoleAssignmentReference struct {
 	RoleID string `json:"roleId,omitempty" xml:"roleId"`
@@ -46,28 +54,59 @@ func NewGroupRoleAssignment(groupKey string, roleID string, scope string) (*Grou
 	}, nil
 }
 
+// NewUserRoleAssignment returns an instance of a UserRoleAssignment. A non-empty username, roleId, and scope is required.
+func NewUserRoleAssignment(username string, roleID string, scope string) (*UserRoleAssignment, error) {
+	if username == "" {
+		return nil, fmt.Errorf("Username is required")
+	}
+
+	if roleID == "" {
+		return nil, fmt.Errorf("RoleId is required")
+	}
+
+	if scope == "" {
+		return nil, fmt.Errorf("scope is required. Use \"g\" at the global level for System Administrators, otherwise for other roles, use \"p:_Root\" for the root project, or \"p:<project_id>\" for other projects")
+	}
+
+	return &UserRoleAssignment{
+		Username: username,
+		RoleID:   roleID,
+		Scope:    scope,
+	}, nil
+}
+
 // RoleAssignmentService has operations for handling role assignments for groups or users
 type RoleAssignmentService struct {
 	groupSling  *sling.Sling
+	userSling   *sling.Sling
 	httpClient  *http.Client
 	groupHelper *restHelper
+	userHelper  *restHelper
 }
 
 func newRoleAssignmentService(base *sling.Sling, httpClient *http.Client) *RoleAssignmentService {
 	groupSling := base.New().Path(fmt.Sprintf("userGroups/"))
+	userSling := base.New().Path("users/")
 	return &RoleAssignmentService{
 		httpClient:  httpClient,
 		groupSling:  groupSling,
+		userSling:   userSling,
 		groupHelper: newRestHelperWithSling(httpClient, groupSling),
+		userHelper:  newRestHelperWithSling(httpClient, userSling),
 	}
 }
 
 // AssignToGroup adds a role assignment to a group
 func (s *RoleAssignmentService) AssignToGroup(assignment *GroupRoleAssignment) (*RoleAssignmentReference, error) {
+	return s.AssignToGroupWithContext(context.Background(), assignment)
+}
+
+// AssignToGroupWithContext adds a role assignment to a group, bound to ctx
+func (s *RoleAssignmentService) AssignToGroupWithContext(ctx context.Context, assignment *GroupRoleAssignment) (*RoleAssignmentReference, error) {
 	var out RoleAssignmentReference
 
 	// URL for assigning role is /app/rest/userGroups/{groupLocator}/roles/{roleId}/{scope}
-	err := s.groupHelper.post(fmt.Sprintf("%s/roles/%s/%s", assignment.GroupKey, assignment.RoleID, assignment.Scope), nil, &out, "AssignToGroup role to group")
+	err := s.groupHelper.post(ctx, fmt.Sprintf("%s/roles/%s/%s", assignment.GroupKey, assignment.RoleID, assignment.Scope), nil, &out, "AssignToGroup role to group")
 	if err != nil {
 		return nil, err
 	}
@@ -76,10 +115,15 @@ func (s *RoleAssignmentService) AssignToGroup(assignment *GroupRoleAssignment) (
 
 // GetForGroup get a specific role assignment for a group
 func (s *RoleAssignmentService) GetForGroup(assignment *GroupRoleAssignment) (*RoleAssignmentReference, error) {
+	return s.GetForGroupWithContext(context.Background(), assignment)
+}
+
+// GetForGroupWithContext get a specific role assignment for a group, bound to ctx
+func (s *RoleAssignmentService) GetForGroupWithContext(ctx context.Context, assignment *GroupRoleAssignment) (*RoleAssignmentReference, error) {
 	var out RoleAssignmentReference
 
 	// URL for getting a specific role assignments is /app/rest/userGroups/{groupLocator}/roles/{roleId}/{scope}
-	err := s.groupHelper.get(fmt.Sprintf("%s/roles/%s/%s", assignment.GroupKey, assignment.RoleID, assignment.Scope), &out, "GetForGroup role assignmens for group")
+	err := s.groupHelper.get(ctx, fmt.Sprintf("%s/roles/%s/%s", assignment.GroupKey, assignment.RoleID, assignment.Scope), &out, "GetForGroup role assignmens for group")
 	if err != nil {
 		return nil, err
 	}
@@ -88,18 +132,131 @@ func (s *RoleAssignmentService) GetForGroup(assignment *GroupRoleAssignment) (*R
 
 // GetAllForGroup gets all the role assignments for a group
 func (s *RoleAssignmentService) GetAllForGroup(group *Group) ([]RoleAssignmentReference, error) {
+	return s.GetAllForGroupWithContext(context.Background(), group)
+}
+
+// GetAllForGroupWithContext gets all the role assignments for a group, bound to ctx
+func (s *RoleAssignmentService) GetAllForGroupWithContext(ctx context.Context, group *Group) ([]RoleAssignmentReference, error) {
 	var aux roleAssignmentsJSON
 
 	// URL for getting role assignments is /app/rest/userGroups/{groupLocator}/roles
-	err := s.groupHelper.get(fmt.Sprintf("%s/roles", group.Key), &aux, "GetForGroup role assignments for group")
+	err := s.groupHelper.get(ctx, fmt.Sprintf("%s/roles", group.Key), &aux, "GetForGroup role assignments for group")
 	if err != nil {
 		return nil, err
 	}
 	return aux.Items, nil
 }
 
+// IterateForGroup walks all the role assignments for a group.
+// TeamCity does not page the roles of a group, so the iterator fetches them all in a single request, like GetAllForGroup.
+func (s *RoleAssignmentService) IterateForGroup(group *Group) *RoleAssignmentIterator {
+	return s.IterateForGroupWithContext(context.Background(), group)
+}
+
+// IterateForGroupWithContext walks all the role assignments for a group, bound to ctx
+func (s *RoleAssignmentService) IterateForGroupWithContext(ctx context.Context, group *Group) *RoleAssignmentIterator {
+	return newRoleAssignmentIterator(ctx, s.groupHelper, fmt.Sprintf("%s/roles", group.Key))
+}
+
 // UnassignFromGroup removes the role assignment from a group
 func (s *RoleAssignmentService) UnassignFromGroup(assignment *GroupRoleAssignment) error {
+	return s.UnassignFromGroupWithContext(context.Background(), assignment)
+}
+
+// UnassignFromGroupWithContext removes the role assignment from a group, bound to ctx
+func (s *RoleAssignmentService) UnassignFromGroupWithContext(ctx context.Context, assignment *GroupRoleAssignment) error {
 	// URL for unassigning role is /app/rest/userGroups/{groupLocator}/roles/{roleId}/{scope}
-	return s.groupHelper.delete(fmt.Sprintf("%s/roles/%s/%s", assignment.GroupKey, assignment.RoleID, assignment.Scope), "UnassignFromGroup role from group")
+	return s.groupHelper.delete(ctx, fmt.Sprintf("%s/roles/%s/%s", assignment.GroupKey, assignment.RoleID, assignment.Scope), "UnassignFromGroup role from group")
+}
+
+// AssignToUser adds a role assignment to a user
+func (s *RoleAssignmentService) AssignToUser(assignment *UserRoleAssignment) (*RoleAssignmentReference, error) {
+	return s.AssignToUserWithContext(context.Background(), assignment)
+}
+
+// AssignToUserWithContext adds a role assignment to a user, bound to ctx
+func (s *RoleAssignmentService) AssignToUserWithContext(ctx context.Context, assignment *UserRoleAssignment) (*RoleAssignmentReference, error) {
+	var out RoleAssignmentReference
+
+	// URL for assigning role is /app/rest/users/{userLocator}/roles/{roleId}/{scope}
+	err := s.userHelper.post(ctx, userRolePath(assignment), nil, &out, "AssignToUser role to user")
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetForUser get a specific role assignment for a user
+func (s *RoleAssignmentService) GetForUser(assignment *UserRoleAssignment) (*RoleAssignmentReference, error) {
+	return s.GetForUserWithContext(context.Background(), assignment)
+}
+
+// GetForUserWithContext get a specific role assignment for a user, bound to ctx
+func (s *RoleAssignmentService) GetForUserWithContext(ctx context.Context, assignment *UserRoleAssignment) (*RoleAssignmentReference, error) {
+	var out RoleAssignmentReference
+
+	err := s.userHelper.get(ctx, userRolePath(assignment), &out, "GetForUser role assignment for user")
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAllForUser gets all the role assignments given to a user directly, not including the ones of its groups
+func (s *RoleAssignmentService) GetAllForUser(user *User) ([]RoleAssignmentReference, error) {
+	return s.GetAllForUserWithContext(context.Background(), user)
+}
+
+// GetAllForUserWithContext gets all the role assignments given to a user directly, bound to ctx
+func (s *RoleAssignmentService) GetAllForUserWithContext(ctx context.Context, user *User) ([]RoleAssignmentReference, error) {
+	var aux roleAssignmentsJSON
+
+	// URL for getting role assignments is /app/rest/users/{userLocator}/roles
+	err := s.userHelper.get(ctx, fmt.Sprintf("%s/roles", LocatorUsername(user.Username)), &aux, "GetAllForUser role assignments for user")
+	if err != nil {
+		return nil, err
+	}
+	return aux.Items, nil
+}
+
+// GetAllUserAssignments lists the role assignments given directly to every user, for auditing who holds a role and where
+func (s *RoleAssignmentService) GetAllUserAssignments() ([]*UserRoleAssignment, error) {
+	return s.GetAllUserAssignmentsWithContext(context.Background())
+}
+
+// GetAllUserAssignmentsWithContext lists the role assignments given directly to every user, bound to ctx
+func (s *RoleAssignmentService) GetAllUserAssignmentsWithContext(ctx context.Context) ([]*UserRoleAssignment, error) {
+	var aux struct {
+		Items []struct {
+			Username string              `json:"username"`
+			Roles    roleAssignmentsJSON `json:"roles"`
+		} `json:"user"`
+	}
+
+	err := s.userHelper.get(ctx, "?fields=user(username,roles(role(roleId,scope,href)))", &aux, "GetAllUserAssignments role assignments for users")
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*UserRoleAssignment
+	for _, u := range aux.Items {
+		for _, r := range u.Roles.Items {
+			out = append(out, &UserRoleAssignment{Username: u.Username, RoleID: r.RoleID, Scope: r.Scope})
+		}
+	}
+	return out, nil
+}
+
+// UnassignFromUser removes the role assignment from a user
+func (s *RoleAssignmentService) UnassignFromUser(assignment *UserRoleAssignment) error {
+	return s.UnassignFromUserWithContext(context.Background(), assignment)
+}
+
+// UnassignFromUserWithContext removes the role assignment from a user, bound to ctx
+func (s *RoleAssignmentService) UnassignFromUserWithContext(ctx context.Context, assignment *UserRoleAssignment) error {
+	return s.userHelper.delete(ctx, userRolePath(assignment), "UnassignFromUser role from user")
+}
+
+func userRolePath(assignment *UserRoleAssignment) string {
+	return fmt.Sprintf("%s/roles/%s/%s", LocatorUsername(assignment.Username), assignment.RoleID, assignment.Scope)
 }
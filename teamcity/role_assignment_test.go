@@ -128,3 +128,42 @@ func TestRoleAssignment_UnassignFromGroup(t *testing.T) {
 	cleanUpGroup(t, client, actualGroup.Key)
 	cleanUpProject(t, client, "ParentProject")
 }
+
+func TestRoleAssignment_AssignToUser(t *testing.T) {
+	client := setup()
+
+	project, _ := teamcity.NewProject("RoleAssignmentProject", "Role Assignment Project", "")
+	created, err := client.Projects.Create(project)
+	require.NoError(t, err)
+	defer cleanUpProject(t, client, created.ID)
+
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "", "P@ssw0rd")
+	actualUser, err := client.Users.Create(newUser)
+	require.NoError(t, err)
+	defer cleanUpUser(t, client, actualUser.Username)
+
+	assignment, _ := teamcity.NewUserRoleAssignment(actualUser.Username, "PROJECT_ADMIN", "p:"+created.ID)
+	createdAssignment, err := client.RoleAssignments.AssignToUser(assignment)
+	require.NoError(t, err)
+	assert.Equal(t, "PROJECT_ADMIN", createdAssignment.RoleID)
+	assert.Equal(t, "p:"+created.ID, createdAssignment.Scope)
+
+	actual, err := client.RoleAssignments.GetForUser(assignment)
+	require.NoError(t, err)
+	assert.Equal(t, createdAssignment.Href, actual.Href)
+
+	userAssignments, err := client.RoleAssignments.GetAllForUser(actualUser)
+	require.NoError(t, err)
+	assert.Contains(t, userAssignments, *createdAssignment)
+
+	all, err := client.RoleAssignments.GetAllUserAssignments()
+	require.NoError(t, err)
+	assert.Contains(t, all, assignment)
+
+	err = client.RoleAssignments.UnassignFromUser(assignment)
+	require.NoError(t, err)
+
+	_, err = client.RoleAssignments.GetForUser(assignment)
+	require.Error(t, err)
+	assert.True(t, teamcity.IsNotFound(err))
+}
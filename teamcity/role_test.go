@@ -0,0 +1,33 @@
+package teamcity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRole_List(t *testing.T) {
+	client := setup()
+
+	actual, err := client.Roles.List()
+
+	require.NoError(t, err)
+	ids := make([]string, 0, len(actual))
+	for _, r := range actual {
+		ids = append(ids, r.ID)
+	}
+	assert.Contains(t, ids, "SYSTEM_ADMIN")
+	assert.Contains(t, ids, "PROJECT_ADMIN")
+}
+
+func TestRole_GetByIDWithPermissions(t *testing.T) {
+	client := setup()
+
+	actual, err := client.Roles.GetByID("PROJECT_DEVELOPER")
+
+	require.NoError(t, err)
+	assert.Equal(t, "PROJECT_DEVELOPER", actual.ID)
+	assert.True(t, actual.HasPermission("RUN_BUILD"))
+	assert.False(t, actual.HasPermission("EDIT_PROJECT"))
+}
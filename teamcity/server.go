@@ -1,6 +1,11 @@
 package teamcity
 
-import "github.com/dghubble/sling"
+import (
+	"context"
+	"net/http"
+
+	"github.com/dghubble/sling"
+)
 
 // Server holds information about the TeamCity server
 type Server struct {
@@ -68,21 +73,28 @@ type Server struct {
 
 // ServerService allows retrieving information about the server
 type ServerService struct {
-	sling *sling.Sling
+	sling      *sling.Sling
+	restHelper *restHelper
 }
 
-func newServerService(base *sling.Sling) *ServerService {
+func newServerService(base *sling.Sling, httpClient *http.Client) *ServerService {
 	return &ServerService{
-		sling: base.Get("server/"),
+		sling:      base.Get("server/"),
+		restHelper: newRestHelperWithSling(httpClient, base),
 	}
 }
 
 // Get returns a struct with server information
 func (s *ServerService) Get() (*Server, error) {
+	return s.GetWithContext(context.Background())
+}
+
+// GetWithContext returns a struct with server information, bound to ctx
+func (s *ServerService) GetWithContext(ctx context.Context) (*Server, error) {
 
 	var out Server
 
-	_, err := s.sling.ReceiveSuccess(&out)
+	err := s.restHelper.get(ctx, "server/", &out, "server")
 
 	if err != nil {
 		return nil, err
@@ -1,73 +1,70 @@
 package teamcity
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
 	"os"
 	"time"
 
 	"github.com/dghubble/sling"
-
-	"github.com/motemen/go-loghttp"
-	// Enable HTTP log tracing
-	_ "github.com/motemen/go-loghttp/global"
 )
 
-type Auth interface{}
-type basicAuth struct {
-	username, password string
-}
-type tokenAuth struct {
-	token string
-}
-
-func BasicAuth(username, password string) Auth {
-	return basicAuth{username, password}
-}
-
-func TokenAuth(token string) Auth {
-	return tokenAuth{token}
-}
-
 //DebugRequests toggle to enable tracing requests to stdout
+//
+//Deprecated: set Client.Logger instead. When no Logger is set, requests are still printed to stdout.
 var DebugRequests = false
 
 //DebugResponses toggle to enable tracing responses to stdout
+//
+//Deprecated: set Client.Logger instead. When no Logger is set, responses are still printed to stdout.
 var DebugResponses = false
 
-func init() {
-	loghttp.DefaultTransport.LogRequest = func(resp *http.Request) {
-		if DebugRequests {
-			debug(httputil.DumpRequest(resp, true))
-		}
-	}
-	loghttp.DefaultTransport.LogResponse = func(resp *http.Response) {
-		if DebugResponses {
-			debug(httputil.DumpResponse(resp, true))
-		}
-	}
-}
-
 //Client represents the base for connecting to TeamCity
 type Client struct {
+	// requestCount numbers requests for logging. It is first to be 64-bit aligned for atomic access.
+	requestCount uint64
+
 	address string
 	baseURI string
+	auth    Auth
+	csrf    csrfCache
 
-	HTTPClient   *http.Client
+	HTTPClient *http.Client
+
+	//RetryTimeout bounds the total time spent retrying a request. When set and RetryPolicy is nil, requests are retried with NewExponentialBackoff().
 	RetryTimeout time.Duration
 
+	//RetryPolicy decides which failed requests are retried. Retries are disabled when both RetryPolicy and RetryTimeout are unset.
+	RetryPolicy RetryPolicy
+
+	//Logger traces every request and response of the client, with secrets redacted. Tracing is disabled when it is nil.
+	Logger Logger
+
+	//LogBodies adds request and response bodies to the records sent to Logger
+	LogBodies bool
+
 	commonBase *sling.Sling
+	restClient *http.Client
 
 	AgentPools      *AgentPoolsService
+	Agents          *AgentService
 	Projects        *ProjectService
 	BuildTypes      *BuildTypeService
+	Builds          *BuildService
+	BuildQueue      *BuildQueueService
+	BuildLogs       *BuildLogService
+	Artifacts       *ArtifactService
 	Server          *ServerService
 	VcsRoots        *VcsRootService
 	Groups          *GroupService
+	Users           *UserService
+	Tokens          *TokenService
 	RoleAssignments *RoleAssignmentService
+	Roles           *RoleService
+	Permissions     *PermissionService
 }
 
 func NewClient(auth Auth, httpClient *http.Client) (*Client, error) {
@@ -87,37 +84,43 @@ func NewClientWithAddress(auth Auth, address string, httpClient *http.Client) (*
 }
 
 func newClientInstance(auth Auth, address string, httpClient *http.Client) (*Client, error) {
+	if auth == nil {
+		return nil, errors.New("unsupported authentication")
+	}
 
+	client := &Client{
+		address:    address,
+		auth:       auth,
+		HTTPClient: httpClient,
+	}
+	restClient := newRestClient(client)
+
+	// Credentials are added by the rest client, so that they are applied anew to every retry of a request
 	sharedClient := sling.New().
+		Doer(restClient).
+		Base(address+auth.BasePath()).
 		Set("Accept", "application/json").
 		Set("Origin", address)
 
-	switch a := auth.(type) {
-	case tokenAuth:
-		sharedClient = sharedClient.
-			Base(address+"/app/rest/").
-			Set("Authorization", fmt.Sprintf("Bearer %s", a.token))
-	case basicAuth:
-		sharedClient = sharedClient.
-			Base(address+"/httpAuth/app/rest/").
-			SetBasicAuth(a.username, a.password)
-
-	default:
-		return nil, errors.New("unsupported authentication")
-	}
-
-	return &Client{
-		address:         address,
-		HTTPClient:      httpClient,
-		commonBase:      sharedClient,
-		AgentPools:      newAgentPoolsService(sharedClient.New(), httpClient),
-		Projects:        newProjectService(sharedClient.New(), httpClient),
-		BuildTypes:      newBuildTypeService(sharedClient.New(), httpClient),
-		Server:          newServerService(sharedClient.New()),
-		VcsRoots:        newVcsRootService(sharedClient.New(), httpClient),
-		Groups:          newGroupService(sharedClient.New(), httpClient),
-		RoleAssignments: newRoleAssignmentService(sharedClient.New(), httpClient),
-	}, nil
+	client.commonBase = sharedClient
+	client.restClient = restClient
+	client.AgentPools = newAgentPoolsService(sharedClient.New(), restClient)
+	client.Agents = newAgentService(sharedClient.New(), restClient)
+	client.Projects = newProjectService(sharedClient.New(), restClient)
+	client.BuildTypes = newBuildTypeService(sharedClient.New(), restClient)
+	client.Builds = newBuildService(sharedClient.New(), restClient)
+	client.BuildQueue = newBuildQueueService(sharedClient.New(), restClient)
+	client.BuildLogs = newBuildLogService(sharedClient.New(), restClient, client.Builds)
+	client.Artifacts = newArtifactService(sharedClient.New(), restClient, client.Builds)
+	client.Server = newServerService(sharedClient.New(), restClient)
+	client.VcsRoots = newVcsRootService(sharedClient.New(), restClient)
+	client.Groups = newGroupService(sharedClient.New(), restClient)
+	client.Users = newUserService(sharedClient.New(), restClient)
+	client.Tokens = newTokenService(sharedClient.New(), restClient)
+	client.RoleAssignments = newRoleAssignmentService(sharedClient.New(), restClient)
+	client.Roles = newRoleService(sharedClient.New(), restClient)
+	client.Permissions = newPermissionService(sharedClient.New(), restClient, client.Roles)
+	return client, nil
 }
 
 // New creates a new client for server address specified at TEAMCITY_ADDR environment variable
@@ -134,48 +137,75 @@ func NewWithAddress(userName, password, address string, httpClient *http.Client)
 
 //AgentRequirementService returns a service to manage agent requirements for a build configuration with given id
 func (c *Client) AgentRequirementService(id string) *AgentRequirementService {
-	return newAgentRequirementService(id, c.HTTPClient, c.commonBase.New())
+	return newAgentRequirementService(id, c.restClient, c.commonBase.New())
 }
 
 //BuildFeatureService returns a service to manage agent requirements for a build configuration with given id
 func (c *Client) BuildFeatureService(id string) *BuildFeatureService {
-	return newBuildFeatureService(id, c.HTTPClient, c.commonBase.New())
+	return newBuildFeatureService(id, c.restClient, c.commonBase.New())
 }
 
 // ProjectFeatureService returns a service to manage project features for a project with given id
 func (c *Client) ProjectFeatureService(id string) *ProjectFeatureService {
-	return newProjectFeatureService(id, c.HTTPClient, c.commonBase.New())
+	return newProjectFeatureService(id, c.restClient, c.commonBase.New())
 }
 
 //DependencyService returns a service to manage snapshot and artifact dependencies for a build configuration with given id
 func (c *Client) DependencyService(id string) *DependencyService {
-	return NewDependencyService(id, c.HTTPClient, c.commonBase.New())
+	return NewDependencyService(id, c.restClient, c.commonBase.New())
 }
 
 //BuildTemplateService returns a service to manage template associations for a build configuration with given id
 func (c *Client) BuildTemplateService(id string) *BuildTemplateService {
-	return NewBuildTemplateService(id, c.HTTPClient, c.commonBase.New())
+	return NewBuildTemplateService(id, c.restClient, c.commonBase.New())
 }
 
 //TriggerService returns a service to manage build triggers for a build configuration with given id
 func (c *Client) TriggerService(buildTypeID string) *TriggerService {
-	return newTriggerService(buildTypeID, c.HTTPClient, c.commonBase.New())
+	return newTriggerService(buildTypeID, c.restClient, c.commonBase.New())
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy
+	}
+	if c.RetryTimeout > 0 {
+		return NewExponentialBackoff()
+	}
+	return nil
 }
 
 // Validate tests if the client is properly configured and can be used
 func (c *Client) Validate() (bool, error) {
-	response, err := c.commonBase.Get("server").ReceiveSuccess(nil)
+	return c.ValidateWithContext(context.Background())
+}
+
+// ValidateWithContext tests if the client is properly configured and can be used, bound to ctx
+func (c *Client) ValidateWithContext(ctx context.Context) (bool, error) {
+	request, err := c.commonBase.New().Get("server").Request()
+	if err != nil {
+		return false, err
+	}
 
+	response, err := c.restClient.Do(request.WithContext(ctx))
 	if err != nil {
 		return false, err
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != 200 && response.StatusCode != 403 {
 		body, err := ioutil.ReadAll(response.Body)
 		if err != nil {
 			return false, err
 		}
-		return false, fmt.Errorf("API error %s: %s", response.Status, body)
+		return false, newAPIError(response, body, "server")
 	}
 
 	return true, nil
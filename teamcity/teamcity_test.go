@@ -1,6 +1,7 @@
 package teamcity_test
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"testing"
@@ -72,3 +73,19 @@ func TestClient_Address(t *testing.T) {
 		assert.Equal(t, true, success)
 	})
 }
+
+func TestClient_ContextCancellation(t *testing.T) {
+	t.Run("Cancelled context aborts the request", func(t *testing.T) {
+		client := setup()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.Projects.GetByIDWithContext(ctx, "_Root")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), context.Canceled.Error())
+
+		_, err = client.ValidateWithContext(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), context.Canceled.Error())
+	})
+}
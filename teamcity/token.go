@@ -0,0 +1,153 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+// Token is an access token of a user, to be used with TokenAuth
+type Token struct {
+	Name           string `json:"name,omitempty" xml:"name"`
+	CreationTime   string `json:"creationTime,omitempty" xml:"creationTime"`
+	ExpirationTime string `json:"expirationTime,omitempty" xml:"expirationTime"`
+
+	// Value is the secret to authenticate with. It is only returned when the token is created.
+	Value string `json:"value,omitempty" xml:"value"`
+
+	// PermissionRestrictions limits what the token can do. A token without restrictions has all the permissions of its user.
+	PermissionRestrictions *TokenPermissionRestrictions `json:"permissionRestrictions,omitempty"`
+}
+
+// TokenPermissionRestrictions is a collection of TokenPermissionRestriction
+type TokenPermissionRestrictions struct {
+	Count int                           `json:"count,omitempty" xml:"count"`
+	Items []*TokenPermissionRestriction `json:"permissionRestriction"`
+}
+
+// TokenPermissionRestriction allows a token a single permission, either server-wide or on a project
+type TokenPermissionRestriction struct {
+	IsGlobalScope bool              `json:"isGlobalScope" xml:"isGlobalScope"`
+	Project       *ProjectReference `json:"project,omitempty"`
+	Permission    *Permission       `json:"permission,omitempty"`
+}
+
+type tokensJSON struct {
+	Count int      `json:"count,omitempty" xml:"count"`
+	Items []*Token `json:"token"`
+}
+
+// NewToken returns an instance of a Token. A non-empty name is required.
+// When expiresAt is not the zero time, the token stops working at that time.
+func NewToken(name string, expiresAt time.Time) (*Token, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Name is required")
+	}
+
+	token := &Token{Name: name}
+	if !expiresAt.IsZero() {
+		token.ExpirationTime = expiresAt.Format(TimeFormat)
+	}
+	return token, nil
+}
+
+// Restrict limits the token to the given permission, such as "run_build", on the project with given id.
+// Use an empty projectID for a server-wide permission. Call it once per permission the token needs.
+func (t *Token) Restrict(permissionID string, projectID string) *Token {
+	if t.PermissionRestrictions == nil {
+		t.PermissionRestrictions = &TokenPermissionRestrictions{}
+	}
+	restriction := &TokenPermissionRestriction{
+		IsGlobalScope: projectID == "",
+		Permission:    &Permission{ID: permissionID},
+	}
+	if projectID != "" {
+		restriction.Project = &ProjectReference{ID: projectID}
+	}
+	t.PermissionRestrictions.Items = append(t.PermissionRestrictions.Items, restriction)
+	t.PermissionRestrictions.Count = len(t.PermissionRestrictions.Items)
+	return t
+}
+
+// CreatedAt parses the creation time of the token
+func (t *Token) CreatedAt() (time.Time, error) {
+	return time.Parse(TimeFormat, t.CreationTime)
+}
+
+// ExpiresAt parses the expiration time of the token. The zero time is returned for tokens that never expire.
+func (t *Token) ExpiresAt() (time.Time, error) {
+	if t.ExpirationTime == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(TimeFormat, t.ExpirationTime)
+}
+
+// TokenService has operations for managing the access tokens of users.
+// Use an empty username to manage the tokens of the user the client is authenticated as.
+type TokenService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+}
+
+func newTokenService(base *sling.Sling, httpClient *http.Client) *TokenService {
+	sling := base.New().Path("users/")
+	return &TokenService{
+		sling:      sling,
+		httpClient: httpClient,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+	}
+}
+
+// List returns the access tokens of a user. Token values are never returned.
+func (s *TokenService) List(username string) ([]*Token, error) {
+	return s.ListWithContext(context.Background(), username)
+}
+
+// ListWithContext returns the access tokens of a user, bound to ctx
+func (s *TokenService) ListWithContext(ctx context.Context, username string) ([]*Token, error) {
+	var out tokensJSON
+	err := s.restHelper.get(ctx, tokensPath(username), &out, "tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Create creates an access token for a user. The returned token holds its Value, which cannot be read again later.
+func (s *TokenService) Create(username string, token *Token) (*Token, error) {
+	return s.CreateWithContext(context.Background(), username, token)
+}
+
+// CreateWithContext creates an access token for a user, bound to ctx
+func (s *TokenService) CreateWithContext(ctx context.Context, username string, token *Token) (*Token, error) {
+	var created Token
+	err := s.restHelper.post(ctx, tokensPath(username), token, &created, "token")
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// Delete revokes the access token with given name of a user
+func (s *TokenService) Delete(username string, name string) error {
+	return s.DeleteWithContext(context.Background(), username, name)
+}
+
+// DeleteWithContext revokes the access token with given name of a user, bound to ctx
+func (s *TokenService) DeleteWithContext(ctx context.Context, username string, name string) error {
+	return s.restHelper.delete(ctx, tokensPath(username)+"/"+url.PathEscape(name), "token")
+}
+
+func tokensPath(username string) string {
+	if username == "" {
+		return "current/tokens"
+	}
+	return fmt.Sprintf("%s/tokens", LocatorUsername(username))
+}
@@ -0,0 +1,68 @@
+package teamcity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenList(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"count":2,"token":[
+		{"name":"ci-bot","creationTime":"20200102T030405+0000","expirationTime":"20200109T030405+0000"},
+		{"name":"legacy","creationTime":"20190102T030405+0000"}
+	]}`, &requests))
+	defer done()
+
+	actual, err := client.Tokens.List("ci.bot")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/httpAuth/app/rest/users/username%3Aci.bot/tokens", requests[0].URI)
+	require.Len(t, actual, 2)
+	expires, err := actual[0].ExpiresAt()
+	require.NoError(t, err)
+	assert.Equal(t, 9, expires.Day())
+	expires, err = actual[1].ExpiresAt()
+	require.NoError(t, err)
+	assert.True(t, expires.IsZero())
+}
+
+func Test_TokenCreateWithExpirationAndScope(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(`{"name":"ci-bot","value":"eyJ0eXAi"}`, &requests))
+	defer done()
+
+	token, err := NewToken("ci-bot", time.Date(2020, 1, 9, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+	token.Restrict("run_build", "Release").Restrict("view_project", "")
+	actual, err := client.Tokens.Create("", token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "eyJ0eXAi", actual.Value)
+	assert.Equal(t, "POST", requests[0].Method)
+	assert.Equal(t, "/httpAuth/app/rest/users/current/tokens", requests[0].URI)
+	assert.JSONEq(t, `{"name":"ci-bot","expirationTime":"20200109T030405+0000","permissionRestrictions":{"count":2,"permissionRestriction":[
+		{"isGlobalScope":false,"project":{"id":"Release"},"permission":{"id":"run_build"}},
+		{"isGlobalScope":true,"permission":{"id":"view_project"}}
+	]}}`, requests[0].Body)
+}
+
+func Test_TokenDelete(t *testing.T) {
+	var requests []recordedRequest
+	client, done := newTestClient(t, recordingHandler(``, &requests))
+	defer done()
+
+	err := client.Tokens.Delete("ci.bot", "old token")
+
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE", requests[0].Method)
+	assert.Equal(t, "/httpAuth/app/rest/users/username%3Aci.bot/tokens/old%20token", requests[0].URI)
+}
+
+func Test_NewTokenRequiresName(t *testing.T) {
+	_, err := NewToken("", time.Time{})
+
+	assert.Error(t, err)
+}
@@ -1,8 +1,8 @@
 package teamcity
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -38,8 +38,13 @@ func newTriggerService(buildTypeID string, c *http.Client, base *sling.Sling) *T
 
 //AddTrigger adds a new build trigger to a build type
 func (s *TriggerService) AddTrigger(t Trigger) (Trigger, error) {
+	return s.AddTriggerWithContext(context.Background(), t)
+}
+
+//AddTriggerWithContext adds a new build trigger to a build type, bound to ctx
+func (s *TriggerService) AddTriggerWithContext(ctx context.Context, t Trigger) (Trigger, error) {
 	var created Trigger
-	err := s.restHelper.postCustom("", t, &created, "build trigger", triggerReadingFunc)
+	err := s.restHelper.postCustom(ctx, "", t, &created, "build trigger", triggerReadingFunc)
 	if err != nil {
 		//Duplicate vcsTrigger for the buildConfiguration - Can't add more than one vcsTrigger
 		if strings.Contains(err.Error(), "Trigger with id 'vcsTrigger'already exists") {
@@ -54,8 +59,13 @@ func (s *TriggerService) AddTrigger(t Trigger) (Trigger, error) {
 
 //GetByID returns a build trigger by its id
 func (s *TriggerService) GetByID(id string) (Trigger, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+//GetByIDWithContext returns a build trigger by its id, bound to ctx
+func (s *TriggerService) GetByIDWithContext(ctx context.Context, id string) (Trigger, error) {
 	var out Trigger
-	err := s.restHelper.getCustom(id, &out, "build trigger", triggerReadingFunc)
+	err := s.restHelper.getCustom(ctx, id, &out, "build trigger", triggerReadingFunc)
 
 	if err != nil {
 		return nil, err
@@ -66,24 +76,10 @@ func (s *TriggerService) GetByID(id string) (Trigger, error) {
 
 //Delete removes a build trigger from the build configuration by its id
 func (s *TriggerService) Delete(id string) error {
-	request, _ := s.base.New().Delete(id).Request()
-	response, err := s.httpClient.Do(request)
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-	if response.StatusCode == 204 {
-		return nil
-	}
-
-	if response.StatusCode != 200 && response.StatusCode != 204 {
-		respData, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Error '%d' when deleting trigger: %s", response.StatusCode, string(respData))
-	}
+	return s.DeleteWithContext(context.Background(), id)
+}
 
-	return nil
+//DeleteWithContext removes a build trigger from the build configuration by its id, bound to ctx
+func (s *TriggerService) DeleteWithContext(ctx context.Context, id string) error {
+	return s.restHelper.delete(ctx, id, "build trigger")
 }
@@ -0,0 +1,270 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dghubble/sling"
+)
+
+// User is the model for user entities in TeamCity
+type User struct {
+	ID       int    `json:"id,omitempty" xml:"id"`
+	Username string `json:"username,omitempty" xml:"username"`
+	Name     string `json:"name,omitempty" xml:"name"`
+	Email    string `json:"email,omitempty" xml:"email"`
+
+	// Password is only sent when creating a user and is never returned by the server
+	Password string `json:"password,omitempty" xml:"password"`
+
+	LastLogin  string      `json:"lastLogin,omitempty" xml:"lastLogin"`
+	Href       string      `json:"href,omitempty" xml:"href"`
+	Properties *Properties `json:"properties,omitempty"`
+	Groups     *Groups     `json:"groups,omitempty"`
+}
+
+// Users is a collection of User
+type Users struct {
+	Count    int     `json:"count,omitempty" xml:"count"`
+	NextHref string  `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*User `json:"user"`
+}
+
+// NewUser returns an instance of a User. A non-empty username is required.
+// Name, email and password can be empty strings and will be omitted.
+func NewUser(username string, name string, email string, password string) (*User, error) {
+	if username == "" {
+		return nil, fmt.Errorf("Username is required")
+	}
+
+	return &User{
+		Username: username,
+		Name:     name,
+		Email:    email,
+		Password: password,
+	}, nil
+}
+
+// UserService has operations for handling users and their group membership.
+// Users are identified by their username, as with GetByUsername.
+type UserService struct {
+	sling      *sling.Sling
+	httpClient *http.Client
+	restHelper *restHelper
+}
+
+func newUserService(base *sling.Sling, httpClient *http.Client) *UserService {
+	sling := base.New().Path("users/")
+	return &UserService{
+		httpClient: httpClient,
+		sling:      sling,
+		restHelper: newRestHelperWithSling(httpClient, sling),
+	}
+}
+
+// Create - Creates a new user
+func (s *UserService) Create(user *User) (*User, error) {
+	return s.CreateWithContext(context.Background(), user)
+}
+
+// CreateWithContext - Creates a new user, bound to ctx
+func (s *UserService) CreateWithContext(ctx context.Context, user *User) (*User, error) {
+	var created User
+	err := s.restHelper.post(ctx, "", user, &created, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// List - Lists all users
+func (s *UserService) List() ([]*User, error) {
+	return s.ListWithContext(context.Background())
+}
+
+// ListWithContext - Lists all users, bound to ctx
+func (s *UserService) ListWithContext(ctx context.Context) ([]*User, error) {
+	var out Users
+	err := s.restHelper.get(ctx, "", &out, "users")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate - Walks all users, fetching pageSize users per request. Use DefaultPageSize when pageSize is not positive.
+func (s *UserService) Iterate(pageSize int) *UserIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
+
+// IterateWithContext - Walks all users page by page, bound to ctx
+func (s *UserService) IterateWithContext(ctx context.Context, pageSize int) *UserIterator {
+	return newUserIterator(ctx, s.restHelper, "?locator="+pagedLocator("", pageSize).String())
+}
+
+// GetByID - Get a user by its id
+func (s *UserService) GetByID(id int) (*User, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext - Get a user by its id, bound to ctx
+func (s *UserService) GetByIDWithContext(ctx context.Context, id int) (*User, error) {
+	return s.getByLocator(ctx, LocatorIDInt(id))
+}
+
+// GetByUsername - Get a user by its username
+func (s *UserService) GetByUsername(username string) (*User, error) {
+	return s.GetByUsernameWithContext(context.Background(), username)
+}
+
+// GetByUsernameWithContext - Get a user by its username, bound to ctx
+func (s *UserService) GetByUsernameWithContext(ctx context.Context, username string) (*User, error) {
+	return s.getByLocator(ctx, LocatorUsername(username))
+}
+
+// GetByEmail - Get a user by its email. Fails if several users share the email.
+func (s *UserService) GetByEmail(email string) (*User, error) {
+	return s.GetByEmailWithContext(context.Background(), email)
+}
+
+// GetByEmailWithContext - Get a user by its email, bound to ctx
+func (s *UserService) GetByEmailWithContext(ctx context.Context, email string) (*User, error) {
+	return s.getByLocator(ctx, LocatorEmail(email))
+}
+
+func (s *UserService) getByLocator(ctx context.Context, locator Locator) (*User, error) {
+	var out User
+	err := s.restHelper.get(ctx, locator.String(), &out, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// SetName - Changes the display name of a user
+func (s *UserService) SetName(username string, name string) error {
+	return s.SetNameWithContext(context.Background(), username, name)
+}
+
+// SetNameWithContext - Changes the display name of a user, bound to ctx
+func (s *UserService) SetNameWithContext(ctx context.Context, username string, name string) error {
+	return s.setField(ctx, username, "name", name)
+}
+
+// SetEmail - Changes the email of a user
+func (s *UserService) SetEmail(username string, email string) error {
+	return s.SetEmailWithContext(context.Background(), username, email)
+}
+
+// SetEmailWithContext - Changes the email of a user, bound to ctx
+func (s *UserService) SetEmailWithContext(ctx context.Context, username string, email string) error {
+	return s.setField(ctx, username, "email", email)
+}
+
+// SetPassword - Changes the password of a user
+func (s *UserService) SetPassword(username string, password string) error {
+	return s.SetPasswordWithContext(context.Background(), username, password)
+}
+
+// SetPasswordWithContext - Changes the password of a user, bound to ctx
+func (s *UserService) SetPasswordWithContext(ctx context.Context, username string, password string) error {
+	return s.setField(ctx, username, "password", password)
+}
+
+func (s *UserService) setField(ctx context.Context, username string, field string, value string) error {
+	_, err := s.restHelper.putTextPlain(ctx, fmt.Sprintf("%s/%s", LocatorUsername(username), field), value, "user "+field)
+	return err
+}
+
+// GetProperties - Get the properties of a user, such as its preferences and VCS usernames
+func (s *UserService) GetProperties(username string) (*Properties, error) {
+	return s.GetPropertiesWithContext(context.Background(), username)
+}
+
+// GetPropertiesWithContext - Get the properties of a user, bound to ctx
+func (s *UserService) GetPropertiesWithContext(ctx context.Context, username string) (*Properties, error) {
+	var out Properties
+	err := s.restHelper.get(ctx, fmt.Sprintf("%s/properties", LocatorUsername(username)), &out, "user properties")
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// SetProperty - Sets a property of a user, creating it if needed
+func (s *UserService) SetProperty(username string, name string, value string) error {
+	return s.SetPropertyWithContext(context.Background(), username, name, value)
+}
+
+// SetPropertyWithContext - Sets a property of a user, bound to ctx
+func (s *UserService) SetPropertyWithContext(ctx context.Context, username string, name string, value string) error {
+	_, err := s.restHelper.putTextPlain(ctx, s.propertyPath(username, name), value, "user property")
+	return err
+}
+
+// DeleteProperty - Removes a property from a user
+func (s *UserService) DeleteProperty(username string, name string) error {
+	return s.DeletePropertyWithContext(context.Background(), username, name)
+}
+
+// DeletePropertyWithContext - Removes a property from a user, bound to ctx
+func (s *UserService) DeletePropertyWithContext(ctx context.Context, username string, name string) error {
+	return s.restHelper.delete(ctx, s.propertyPath(username, name), "user property")
+}
+
+func (s *UserService) propertyPath(username string, name string) string {
+	return fmt.Sprintf("%s/properties/%s", LocatorUsername(username), url.PathEscape(name))
+}
+
+// GetGroups - Get the groups a user is a direct member of
+func (s *UserService) GetGroups(username string) ([]*Group, error) {
+	return s.GetGroupsWithContext(context.Background(), username)
+}
+
+// GetGroupsWithContext - Get the groups a user is a direct member of, bound to ctx
+func (s *UserService) GetGroupsWithContext(ctx context.Context, username string) ([]*Group, error) {
+	var out Groups
+	err := s.restHelper.get(ctx, fmt.Sprintf("%s/groups", LocatorUsername(username)), &out, "user groups")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// AddToGroup - Adds a user to the group with the given key
+func (s *UserService) AddToGroup(username string, groupKey string) error {
+	return s.AddToGroupWithContext(context.Background(), username, groupKey)
+}
+
+// AddToGroupWithContext - Adds a user to the group with the given key, bound to ctx
+func (s *UserService) AddToGroupWithContext(ctx context.Context, username string, groupKey string) error {
+	var out Group
+	return s.restHelper.post(ctx, fmt.Sprintf("%s/groups", LocatorUsername(username)), &Group{Key: groupKey}, &out, "user group")
+}
+
+// RemoveFromGroup - Removes a user from the group with the given key
+func (s *UserService) RemoveFromGroup(username string, groupKey string) error {
+	return s.RemoveFromGroupWithContext(context.Background(), username, groupKey)
+}
+
+// RemoveFromGroupWithContext - Removes a user from the group with the given key, bound to ctx
+func (s *UserService) RemoveFromGroupWithContext(ctx context.Context, username string, groupKey string) error {
+	return s.restHelper.delete(ctx, fmt.Sprintf("%s/groups/%s", LocatorUsername(username), LocatorKey(groupKey)), "user group")
+}
+
+// Delete - Deletes a user by its username
+func (s *UserService) Delete(username string) error {
+	return s.DeleteWithContext(context.Background(), username)
+}
+
+// DeleteWithContext - Deletes a user by its username, bound to ctx
+func (s *UserService) DeleteWithContext(ctx context.Context, username string) error {
+	return s.restHelper.delete(ctx, LocatorUsername(username).String(), "user")
+}
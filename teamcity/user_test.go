@@ -0,0 +1,126 @@
+package teamcity_test
+
+import (
+	"testing"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser_Create(t *testing.T) {
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "test.user@example.com", "P@ssw0rd")
+	client := setup()
+	actual, err := client.Users.Create(newUser)
+
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	cleanUpUser(t, client, actual.Username)
+
+	assert.NotZero(t, actual.ID)
+	assert.Equal(t, newUser.Username, actual.Username)
+	assert.Equal(t, newUser.Name, actual.Name)
+	assert.Equal(t, newUser.Email, actual.Email)
+	assert.Empty(t, actual.Password)
+}
+
+func TestUser_GetByUsernameIDAndEmail(t *testing.T) {
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "test.user@example.com", "P@ssw0rd")
+	client := setup()
+	created, err := client.Users.Create(newUser)
+	require.NoError(t, err)
+	defer cleanUpUser(t, client, created.Username)
+
+	byUsername, err := client.Users.GetByUsername(newUser.Username)
+	require.NoError(t, err)
+	byID, err := client.Users.GetByID(created.ID)
+	require.NoError(t, err)
+	byEmail, err := client.Users.GetByEmail(newUser.Email)
+	require.NoError(t, err)
+
+	assert.Equal(t, created.ID, byUsername.ID)
+	assert.Equal(t, created.ID, byID.ID)
+	assert.Equal(t, created.ID, byEmail.ID)
+}
+
+func TestUser_UpdateFields(t *testing.T) {
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "", "P@ssw0rd")
+	client := setup()
+	client.Users.Create(newUser)
+	defer cleanUpUser(t, client, newUser.Username)
+
+	require.NoError(t, client.Users.SetName(newUser.Username, "Renamed User"))
+	require.NoError(t, client.Users.SetEmail(newUser.Username, "renamed@example.com"))
+	require.NoError(t, client.Users.SetPassword(newUser.Username, "N3wP@ssw0rd"))
+
+	actual, err := client.Users.GetByUsername(newUser.Username)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed User", actual.Name)
+	assert.Equal(t, "renamed@example.com", actual.Email)
+}
+
+func TestUser_Properties(t *testing.T) {
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "", "P@ssw0rd")
+	client := setup()
+	client.Users.Create(newUser)
+	defer cleanUpUser(t, client, newUser.Username)
+
+	require.NoError(t, client.Users.SetProperty(newUser.Username, "plugin:vcs:anyVcs:anyVcsRoot", "tuser"))
+	props, err := client.Users.GetProperties(newUser.Username)
+	require.NoError(t, err)
+	value, ok := props.GetOk("plugin:vcs:anyVcs:anyVcsRoot")
+	assert.True(t, ok)
+	assert.Equal(t, "tuser", value)
+
+	require.NoError(t, client.Users.DeleteProperty(newUser.Username, "plugin:vcs:anyVcs:anyVcsRoot"))
+	props, err = client.Users.GetProperties(newUser.Username)
+	require.NoError(t, err)
+	_, ok = props.GetOk("plugin:vcs:anyVcs:anyVcsRoot")
+	assert.False(t, ok)
+}
+
+func TestUser_GroupMembership(t *testing.T) {
+	newGroup, _ := teamcity.NewGroup("TESTGROUPKEY", "Test Group Name", "")
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "", "P@ssw0rd")
+	client := setup()
+	client.Groups.Create(newGroup)
+	defer cleanUpGroup(t, client, newGroup.Key)
+	client.Users.Create(newUser)
+	defer cleanUpUser(t, client, newUser.Username)
+
+	require.NoError(t, client.Users.AddToGroup(newUser.Username, newGroup.Key))
+	groups, err := client.Users.GetGroups(newUser.Username)
+	require.NoError(t, err)
+	assert.True(t, containsGroup(groups, newGroup.Key))
+
+	require.NoError(t, client.Users.RemoveFromGroup(newUser.Username, newGroup.Key))
+	groups, err = client.Users.GetGroups(newUser.Username)
+	require.NoError(t, err)
+	assert.False(t, containsGroup(groups, newGroup.Key))
+}
+
+func TestUser_Delete(t *testing.T) {
+	newUser, _ := teamcity.NewUser("test.user", "Test User", "", "P@ssw0rd")
+	client := setup()
+	client.Users.Create(newUser)
+
+	err := client.Users.Delete(newUser.Username)
+	require.NoError(t, err)
+
+	_, err = client.Users.GetByUsername(newUser.Username)
+	require.Error(t, err)
+	assert.True(t, teamcity.IsNotFound(err))
+}
+
+func containsGroup(groups []*teamcity.Group, key string) bool {
+	for _, g := range groups {
+		if g.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanUpUser(t *testing.T, client *teamcity.Client, username string) {
+	client.Users.Delete(username)
+}
@@ -1,10 +1,12 @@
 package teamcity
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"github.com/dghubble/sling"
 )
@@ -81,6 +83,13 @@ type VcsRootReference struct {
 	Project *ProjectReference `json:"project,omitempty" xml:"project"`
 }
 
+// VcsRootReferences is a collection of VcsRootReference
+type VcsRootReferences struct {
+	Count    int                 `json:"count,omitempty" xml:"count"`
+	NextHref string              `json:"nextHref,omitempty" xml:"nextHref"`
+	Items    []*VcsRootReference `json:"vcs-root"`
+}
+
 // VcsRootService has operations for handling vcs roots
 type VcsRootService struct {
 	sling      *sling.Sling
@@ -99,9 +108,14 @@ func newVcsRootService(base *sling.Sling, httpClient *http.Client) *VcsRootServi
 
 // Create creates a new vcs root
 func (s *VcsRootService) Create(projectID string, vcsRoot VcsRoot) (*VcsRootReference, error) {
+	return s.CreateWithContext(context.Background(), projectID, vcsRoot)
+}
+
+// CreateWithContext creates a new vcs root, bound to ctx
+func (s *VcsRootService) CreateWithContext(ctx context.Context, projectID string, vcsRoot VcsRoot) (*VcsRootReference, error) {
 	var created VcsRootReference
 
-	err := s.restHelper.post("", vcsRoot, &created, "VcsRoot")
+	err := s.restHelper.post(ctx, "", vcsRoot, &created, "VcsRoot")
 
 	if err != nil {
 		return nil, err
@@ -114,28 +128,33 @@ func (s *VcsRootService) Create(projectID string, vcsRoot VcsRoot) (*VcsRootRefe
 //TeamCity API does not support "PUT" on the whole VCS Root resource. Updateable fields are "name", "project" and "modificationCheckInterval".
 //This method also updates Settings and Parameters, but this is not an atomic operation. If an error occurs, it will be returned to caller what was updated or not.
 func (s *VcsRootService) Update(vcsRoot VcsRoot) (VcsRoot, error) {
+	return s.UpdateWithContext(context.Background(), vcsRoot)
+}
+
+//UpdateWithContext changes the resource in-place for a VCS Root, bound to ctx. See Update for the caveats.
+func (s *VcsRootService) UpdateWithContext(ctx context.Context, vcsRoot VcsRoot) (VcsRoot, error) {
 	var props Properties
 
 	//Do a diff change update. Since properties can only be modified individually, check for changes before sending requests.
-	dt, err := s.GetByID(vcsRoot.GetID())
+	dt, err := s.GetByIDWithContext(ctx, vcsRoot.GetID())
 	if err != nil {
 		return nil, fmt.Errorf("could not refresh VcsRoot for diff prior to update: %s", err)
 	}
 
-	err = s.restHelper.put(fmt.Sprintf("%s/properties", dt.GetID()), vcsRoot.Properties(), &props, "VcsRoot")
+	err = s.restHelper.put(ctx, fmt.Sprintf("%s/properties", dt.GetID()), vcsRoot.Properties(), &props, "VcsRoot")
 	if err != nil {
 		return nil, err
 	}
 
 	if dt.Name() != vcsRoot.Name() {
-		_, err = s.restHelper.putTextPlain(fmt.Sprintf("%s/name", vcsRoot.GetID()), vcsRoot.Name(), "VcsRoot name field")
+		_, err = s.restHelper.putTextPlain(ctx, fmt.Sprintf("%s/name", vcsRoot.GetID()), vcsRoot.Name(), "VcsRoot name field")
 		if err != nil {
 			return nil, fmt.Errorf("error when updating 'name' field for VcsRoot. Resource may be in partial update state. %s", err)
 		}
 	}
 
 	if dt.ProjectID() != vcsRoot.ProjectID() {
-		_, err = s.restHelper.putTextPlain(fmt.Sprintf("%s/projectId", vcsRoot.GetID()), vcsRoot.ProjectID(), "VcsRoot projectId field")
+		_, err = s.restHelper.putTextPlain(ctx, fmt.Sprintf("%s/projectId", vcsRoot.GetID()), vcsRoot.ProjectID(), "VcsRoot projectId field")
 		if err != nil {
 			return nil, fmt.Errorf("error when updating 'projectId' field for VcsRoot. Resource may be in partial update state. %s", err)
 		}
@@ -143,14 +162,14 @@ func (s *VcsRootService) Update(vcsRoot VcsRoot) (VcsRoot, error) {
 
 	if dt.ModificationCheckInterval() != vcsRoot.ModificationCheckInterval() && vcsRoot.ModificationCheckInterval() != nil {
 		v := vcsRoot.ModificationCheckInterval()
-		_, err = s.restHelper.putTextPlain(fmt.Sprintf("%s/modificationCheckInterval", vcsRoot.GetID()), fmt.Sprintf("%d", *v), "VcsRoot modificationCheckInterval field")
+		_, err = s.restHelper.putTextPlain(ctx, fmt.Sprintf("%s/modificationCheckInterval", vcsRoot.GetID()), fmt.Sprintf("%d", *v), "VcsRoot modificationCheckInterval field")
 		if err != nil {
 			return nil, fmt.Errorf("error when updating 'modificationCheckInterval' field for VcsRoot. Resource may be in partial update state. %s", err)
 		}
 	}
 
 	//Refresh after update
-	updated, err := s.GetByID(vcsRoot.GetID())
+	updated, err := s.GetByIDWithContext(ctx, vcsRoot.GetID())
 	if err != nil {
 		return nil, err
 	}
@@ -158,15 +177,48 @@ func (s *VcsRootService) Update(vcsRoot VcsRoot) (VcsRoot, error) {
 	return updated, nil
 }
 
+// ListReferences returns references to all vcs roots, fetching only the fields of VcsRootReference.
+// Full vcs roots need their type and properties to be read, so this is how to read vcs roots partially.
+func (s *VcsRootService) ListReferences() ([]*VcsRootReference, error) {
+	return s.ListReferencesWithContext(context.Background())
+}
+
+// ListReferencesWithContext returns references to all vcs roots, bound to ctx
+func (s *VcsRootService) ListReferencesWithContext(ctx context.Context) ([]*VcsRootReference, error) {
+	var out VcsRootReferences
+	err := s.restHelper.get(ctx, NewFields("count").Nested("vcs-root", VcsRootReferenceFields).query(), &out, "vcs roots")
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Items, nil
+}
+
+// Iterate walks references to all vcs roots, fetching pageSize vcs roots per request. Use DefaultPageSize when pageSize is not positive.
+func (s *VcsRootService) Iterate(pageSize int) *VcsRootIterator {
+	return s.IterateWithContext(context.Background(), pageSize)
+}
+
+// IterateWithContext walks references to all vcs roots page by page, bound to ctx
+func (s *VcsRootService) IterateWithContext(ctx context.Context, pageSize int) *VcsRootIterator {
+	fields := NewFields("count", "nextHref").Nested("vcs-root", VcsRootReferenceFields)
+	return newVcsRootIterator(ctx, s.restHelper, "?locator="+pagedLocator("", pageSize).String()+"&fields="+url.QueryEscape(fields.String()))
+}
+
 // GetByID Retrieves a vcs root by id using the id: locator
 func (s *VcsRootService) GetByID(id string) (VcsRoot, error) {
+	return s.GetByIDWithContext(context.Background(), id)
+}
+
+// GetByIDWithContext Retrieves a vcs root by id using the id: locator, bound to ctx
+func (s *VcsRootService) GetByIDWithContext(ctx context.Context, id string) (VcsRoot, error) {
 	req, err := s.sling.New().Get(id).Request()
 
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
 
 	if err != nil {
 		return nil, err
@@ -175,7 +227,8 @@ func (s *VcsRootService) GetByID(id string) (VcsRoot, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Error when retrieving VcsRoot id = '%s', status: %d", id, resp.StatusCode)
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, newAPIError(resp, body, "VcsRoot")
 	}
 
 	return s.readVcsRootResponse(resp)
@@ -183,28 +236,12 @@ func (s *VcsRootService) GetByID(id string) (VcsRoot, error) {
 
 //Delete a VCS Root resource using id: locator
 func (s *VcsRootService) Delete(id string) error {
-	request, _ := s.sling.New().Delete(id).Request()
-
-	//TODO: Expose the same httpClient used by sling
-	response, err := s.httpClient.Do(request)
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-	if response.StatusCode == 204 {
-		return nil
-	}
-
-	if response.StatusCode != 200 && response.StatusCode != 204 {
-		respData, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Error '%d' when deleting vcsRoot: %s", response.StatusCode, string(respData))
-	}
+	return s.DeleteWithContext(context.Background(), id)
+}
 
-	return nil
+//DeleteWithContext deletes a VCS Root resource using id: locator, bound to ctx
+func (s *VcsRootService) DeleteWithContext(ctx context.Context, id string) error {
+	return s.restHelper.delete(ctx, id, "VcsRoot")
 }
 
 func (s *VcsRootService) readVcsRootResponse(resp *http.Response) (VcsRoot, error) {
@@ -0,0 +1,153 @@
+package teamcitytest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+)
+
+func (s *Server) routeAgentPools(r *request) (interface{}, error) {
+	segments := r.segments[1:]
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			pools := s.agentPools
+			l, err := teamcity.ParseLocator(r.query.Get("locator"))
+			if err != nil {
+				return nil, badRequest("%s", err)
+			}
+			// Pools can be listed for a project, with the locator "project:(id:X)"
+			project, ok, err := l.LookupSub("project")
+			if err != nil {
+				return nil, badRequest("%s", err)
+			}
+			if ok {
+				pools = filter(pools, func(pool object) bool {
+					return len(filter(list(pool, "projects", "project"), func(p object) bool { return matches(project, p, "id") })) > 0
+				})
+			}
+			out := make([]object, len(pools))
+			for i, pool := range pools {
+				out[i] = s.agentPoolRef(pool)
+			}
+			return collection("agentPool", out), nil
+		case http.MethodPost:
+			return s.createAgentPool(r)
+		}
+		return nil, nil
+	}
+
+	i := find(s.agentPools, segments[0], "id")
+	if i < 0 {
+		return nil, notFound("No agent pool found by locator '%s'.", segments[0])
+	}
+	pool := s.agentPools[i]
+	if len(segments) == 1 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderAgentPool(pool), nil
+		case http.MethodDelete:
+			if str(pool["id"]) == "0" {
+				return nil, badRequest("Default agent pool cannot be deleted")
+			}
+			s.agentPools = append(s.agentPools[:i], s.agentPools[i+1:]...)
+			return deleted, nil
+		}
+		return nil, nil
+	}
+
+	if segments[1] == "projects" {
+		return s.servePoolProjects(pool, r, segments[2:])
+	}
+	return nil, nil
+}
+
+func (s *Server) createAgentPool(r *request) (interface{}, error) {
+	body, err := r.json()
+	if err != nil {
+		return nil, err
+	}
+	name := str(body["name"])
+	if name == "" {
+		return nil, badRequest("Agent pool name cannot be empty")
+	}
+	if find(s.agentPools, "name:"+name, "id") >= 0 {
+		return nil, badRequest("Agent pool with name \"%s\" already exists", name)
+	}
+
+	// Pool ids are numbers, assigned in sequence
+	id := 0
+	for _, pool := range s.agentPools {
+		if n, _ := strconv.Atoi(str(pool["id"])); n >= id {
+			id = n + 1
+		}
+	}
+	pool := object{"id": id, "name": name}
+	if v, ok := body["maxAgents"]; ok {
+		pool["maxAgents"] = v
+	}
+	setList(pool, "projects", "project", nil)
+
+	s.agentPools = append(s.agentPools, pool)
+	return s.renderAgentPool(pool), nil
+}
+
+// servePoolProjects handles the projects assigned to a pool, stored as references holding only their id
+func (s *Server) servePoolProjects(pool object, r *request, segments []string) (interface{}, error) {
+	assigned := list(pool, "projects", "project")
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderAgentPool(pool)["projects"], nil
+		case http.MethodPost:
+			body, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			project := s.project(refID(body))
+			if project == nil {
+				return nil, notFound("No project found by locator 'id:%s'.", refID(body))
+			}
+			if find(assigned, "id:"+str(project["id"]), "id") < 0 {
+				setList(pool, "projects", "project", append(assigned, object{"id": project["id"]}))
+			}
+			return s.renderProject(project), nil
+		}
+		return nil, nil
+	}
+
+	i := find(assigned, segments[0], "id")
+	if i < 0 || len(segments) > 1 {
+		return nil, notFound("No project found by locator '%s' in agent pool '%s'.", segments[0], pool["name"])
+	}
+	if r.method == http.MethodDelete {
+		setList(pool, "projects", "project", append(assigned[:i], assigned[i+1:]...))
+		return deleted, nil
+	}
+	return nil, nil
+}
+
+func (s *Server) agentPoolRef(pool object) object {
+	return object{
+		"id":   pool["id"],
+		"name": pool["name"],
+		"href": s.href("agentPools", "id:"+str(pool["id"])),
+	}
+}
+
+func (s *Server) renderAgentPool(pool object) object {
+	out := copyObject(pool)
+	for k, v := range s.agentPoolRef(pool) {
+		out[k] = v
+	}
+	var projects []object
+	for _, ref := range list(pool, "projects", "project") {
+		if project := s.project(str(ref["id"])); project != nil {
+			projects = append(projects, s.projectRef(project))
+		}
+	}
+	out["projects"] = collection("project", projects)
+	out["agents"] = collection("agent", nil)
+	return out
+}
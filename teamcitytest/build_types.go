@@ -0,0 +1,398 @@
+package teamcitytest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// buildTypeCollections are the collections of a build configuration handled generically, by path
+var buildTypeCollections = map[string]subCollection{
+	"steps":                 {field: "steps", itemField: "step", idPrefix: "RUNNER_"},
+	"features":              {field: "features", itemField: "feature", idPrefix: "BUILD_EXT_"},
+	"triggers":              {field: "triggers", itemField: "trigger", idPrefix: "TRIGGER_"},
+	"agent-requirements":    {field: "agent-requirements", itemField: "agent-requirement", idPrefix: "RQ_"},
+	"artifact-dependencies": {field: "artifact-dependencies", itemField: "artifact-dependency", idPrefix: "ARTIFACT_DEPENDENCY_"},
+	"snapshot-dependencies": {field: "snapshot-dependencies", itemField: "snapshot-dependency", idOf: func(item object) string {
+		return refID(item["source-buildType"])
+	}},
+	"vcs-root-entries": {field: "vcs-root-entries", itemField: "vcs-root-entry", idOf: func(item object) string {
+		return refID(item["vcs-root"])
+	}},
+}
+
+func initBuildType(b object) {
+	for _, c := range buildTypeCollections {
+		if _, ok := b[c.field]; !ok {
+			setList(b, c.field, c.itemField, nil)
+		}
+	}
+	for _, field := range []string{"settings", "parameters"} {
+		if _, ok := b[field]; !ok {
+			setList(b, field, "property", nil)
+		}
+	}
+	if _, ok := b["templates"]; !ok {
+		setList(b, "templates", "buildType", nil)
+	}
+	b["templateFlag"] = b["templateFlag"] == true
+	b["paused"] = b["paused"] == true
+}
+
+func (s *Server) routeBuildTypes(r *request) (interface{}, error) {
+	segments := r.segments[1:]
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			return s.listBuildTypes(r)
+		case http.MethodPost:
+			return s.createBuildType(r)
+		}
+		return nil, nil
+	}
+
+	i := find(s.buildTypes, segments[0], "id")
+	if i < 0 {
+		return nil, notFound("No build type or template is found by id, internal id or name '%s'.", segments[0])
+	}
+	buildType := s.buildTypes[i]
+	if len(segments) == 1 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderBuildType(buildType), nil
+		case http.MethodDelete:
+			s.buildTypes = append(s.buildTypes[:i], s.buildTypes[i+1:]...)
+			return deleted, nil
+		}
+		return nil, nil
+	}
+
+	switch segments[1] {
+	case "name", "description":
+		if len(segments) == 2 {
+			return serveTextField(buildType, segments[1], "", r)
+		}
+	case "paused":
+		if len(segments) == 2 {
+			return serveTextField(buildType, segments[1], "bool", r)
+		}
+	case "settings", "parameters":
+		return serveProperties(buildType, segments[1], r, segments[2:])
+	case "templates":
+		return s.serveTemplates(buildType, r, segments[2:])
+	case "vcs-root-entries":
+		if r.method == http.MethodPost && len(segments) == 2 {
+			body, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			if id := refID(body["vcs-root"]); find(s.vcsRoots, "id:"+id, "id") < 0 {
+				return nil, notFound("No VCS root found by locator 'id:%s'.", id)
+			}
+		}
+	}
+	if c, ok := buildTypeCollections[segments[1]]; ok {
+		return s.serveSubCollection(c, buildType, r, segments[2:])
+	}
+	return nil, nil
+}
+
+// listBuildTypes lists the build types selected by the locator of the request, as references unless fields select more.
+// Like TeamCity, templates are only listed when the locator asks for them with templateFlag.
+func (s *Server) listBuildTypes(r *request) (interface{}, error) {
+	l, err := teamcity.ParseLocator(r.query.Get("locator"))
+	if err != nil {
+		return nil, badRequest("%s", err)
+	}
+	projectID, byProject, err := lookupID(l, "project")
+	if err != nil {
+		return nil, badRequest("%s", err)
+	}
+	affectedID, byAffected, err := lookupID(l, "affectedProject")
+	if err != nil {
+		return nil, badRequest("%s", err)
+	}
+	vcsRootID, byVcsRoot, err := lookupID(l, "vcsRoot")
+	if err != nil {
+		return nil, badRequest("%s", err)
+	}
+	templateID, byTemplate, err := lookupID(l, "template")
+	if err != nil {
+		return nil, badRequest("%s", err)
+	}
+	var parameters []*teamcity.LocatorBuilder
+	for _, d := range l.Dimensions() {
+		if d.Name != "parameter" {
+			continue
+		}
+		parameter, err := teamcity.ParseLocator(d.Value)
+		if err != nil {
+			return nil, badRequest("%s", err)
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	keep := func(b object) bool {
+		if byProject && str(b["projectId"]) != projectID {
+			return false
+		}
+		if byAffected && !s.affectedBy(str(b["projectId"]), affectedID) {
+			return false
+		}
+		switch flag, _ := l.Lookup("templateFlag"); flag {
+		case "any":
+		case "true", "false":
+			if str(b["templateFlag"]) != flag {
+				return false
+			}
+		default:
+			if b["templateFlag"] == true {
+				return false
+			}
+		}
+		if paused, ok := l.Lookup("paused"); ok && str(b["paused"]) != paused {
+			return false
+		}
+		if byVcsRoot {
+			entries := filter(list(b, "vcs-root-entries", "vcs-root-entry"), func(e object) bool { return refID(e["vcs-root"]) == vcsRootID })
+			if len(entries) == 0 {
+				return false
+			}
+		}
+		if byTemplate && len(filter(list(b, "templates", "buildType"), func(t object) bool { return str(t["id"]) == templateID })) == 0 {
+			return false
+		}
+		for _, p := range parameters {
+			if !hasParameter(b, p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	fields := buildTypeFields(r.query.Get("fields"))
+	var out []object
+	for _, b := range filter(s.buildTypes, keep) {
+		if len(fields) == 0 {
+			out = append(out, s.buildTypeRef(b))
+			continue
+		}
+		full := s.renderBuildType(b)
+		selected := object{}
+		for field := range fields {
+			if v, ok := full[field]; ok {
+				selected[field] = v
+			}
+		}
+		out = append(out, selected)
+	}
+	return collection("buildType", out), nil
+}
+
+// affectedBy reports whether the project with given id is ancestorID or one of its subprojects
+func (s *Server) affectedBy(projectID string, ancestorID string) bool {
+	for projectID != "" {
+		if projectID == ancestorID {
+			return true
+		}
+		project := s.project(projectID)
+		if project == nil {
+			return false
+		}
+		projectID = str(project["parentProjectId"])
+	}
+	return false
+}
+
+// hasParameter reports whether the build type defines the parameter of a "name:X,value:Y,matchType:equals" locator
+func hasParameter(b object, l *teamcity.LocatorBuilder) bool {
+	name, _ := l.Lookup("name")
+	for _, p := range list(b, "parameters", "property") {
+		if str(p["name"]) != name {
+			continue
+		}
+		if value, ok := l.Lookup("value"); !ok || str(p["value"]) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// buildTypeFields returns the fields selected for each build type by a fields parameter such as
+// "count,buildType(id,parameters($long))". Nested selections are not applied, nested fields are returned whole.
+func buildTypeFields(fields string) map[string]bool {
+	const prefix = "buildType("
+	start := strings.Index(fields, prefix)
+	if start < 0 {
+		return nil
+	}
+	out := map[string]bool{}
+	depth, name := 0, ""
+	for _, c := range fields[start+len(prefix):] {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')' && depth == 0:
+			if name != "" {
+				out[name] = true
+			}
+			return out
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			out[name] = true
+			name = ""
+		case depth == 0:
+			name += string(c)
+		}
+	}
+	return out
+}
+
+func (s *Server) createBuildType(r *request) (interface{}, error) {
+	body, err := r.json()
+	if err != nil {
+		return nil, err
+	}
+	name := str(body["name"])
+	if name == "" {
+		return nil, badRequest("When creating a build type, non empty name should be specified")
+	}
+	projectID := str(body["projectId"])
+	if projectID == "" {
+		projectID = refID(body["project"])
+	}
+	if s.project(projectID) == nil {
+		return nil, notFound("No project found by locator 'id:%s'.", projectID)
+	}
+	for _, b := range s.buildTypes {
+		if str(b["projectId"]) == projectID && str(b["name"]) == name {
+			return nil, badRequest("Build configuration or template with name \"%s\" already exists in project", name)
+		}
+	}
+
+	id := str(body["id"])
+	exists := func(id string) bool { return find(s.buildTypes, "id:"+id, "id") >= 0 }
+	if id == "" {
+		id = uniqueID(projectID, name, exists)
+	} else if exists(id) {
+		return nil, badRequest("The build configuration / template ID \"%s\" is already used by another configuration or template", id)
+	}
+
+	buildType := object{}
+	for _, field := range []string{"name", "description", "templateFlag", "paused", "settings", "parameters", "steps", "templates"} {
+		if v, ok := body[field]; ok {
+			buildType[field] = v
+		}
+	}
+	buildType["id"] = id
+	buildType["projectId"] = projectID
+	initBuildType(buildType)
+	steps := list(buildType, "steps", "step")
+	for i, step := range steps {
+		s.assignID(buildTypeCollections["steps"], step, steps[:i])
+	}
+
+	s.buildTypes = append(s.buildTypes, buildType)
+	return s.renderBuildType(buildType), nil
+}
+
+// serveTemplates handles the templates attached to a build configuration, stored as references holding only their id
+func (s *Server) serveTemplates(buildType object, r *request, segments []string) (interface{}, error) {
+	attached := list(buildType, "templates", "buildType")
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderBuildType(buildType)["templates"], nil
+		case http.MethodPost:
+			body, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			template, err := s.template(str(body["id"]))
+			if err != nil {
+				return nil, err
+			}
+			// Attaching an attached template again changes nothing
+			if find(attached, "id:"+str(body["id"]), "id") < 0 {
+				setList(buildType, "templates", "buildType", append(attached, object{"id": template["id"]}))
+			}
+			return s.buildTypeRef(template), nil
+		case http.MethodPut:
+			body, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			var refs []object
+			for _, ref := range list(object{"templates": body}, "templates", "buildType") {
+				template, err := s.template(str(ref["id"]))
+				if err != nil {
+					return nil, err
+				}
+				refs = append(refs, object{"id": template["id"]})
+			}
+			setList(buildType, "templates", "buildType", refs)
+			return s.renderBuildType(buildType)["templates"], nil
+		}
+		return nil, nil
+	}
+
+	i := find(attached, segments[0], "id")
+	if i < 0 || len(segments) > 1 {
+		return nil, notFound("No template found by locator '%s' attached to build type '%s'.", segments[0], buildType["id"])
+	}
+	switch r.method {
+	case http.MethodGet:
+		return s.buildTypeRef(s.buildTypes[find(s.buildTypes, "id:"+str(attached[i]["id"]), "id")]), nil
+	case http.MethodDelete:
+		setList(buildType, "templates", "buildType", append(attached[:i], attached[i+1:]...))
+		return deleted, nil
+	}
+	return nil, nil
+}
+
+// template returns the build configuration template with given id
+func (s *Server) template(id string) (object, error) {
+	i := find(s.buildTypes, "id:"+id, "id")
+	if i < 0 || s.buildTypes[i]["templateFlag"] != true {
+		return nil, notFound("No build type template found by locator 'id:%s'.", id)
+	}
+	return s.buildTypes[i], nil
+}
+
+func (s *Server) buildTypeRef(b object) object {
+	ref := object{
+		"id":        b["id"],
+		"name":      b["name"],
+		"projectId": b["projectId"],
+		"href":      s.href("buildTypes", "id:"+str(b["id"])),
+		"webUrl":    s.URL + "/viewType.html?buildTypeId=" + str(b["id"]),
+	}
+	if b["templateFlag"] == true {
+		ref["templateFlag"] = true
+	}
+	if project := s.project(str(b["projectId"])); project != nil {
+		ref["projectName"] = project["name"]
+	}
+	return ref
+}
+
+func (s *Server) renderBuildType(b object) object {
+	out := copyObject(b)
+	for k, v := range s.buildTypeRef(b) {
+		out[k] = v
+	}
+	if project := s.project(str(b["projectId"])); project != nil {
+		out["project"] = s.projectRef(project)
+	}
+
+	var templates []object
+	for _, t := range list(b, "templates", "buildType") {
+		if i := find(s.buildTypes, "id:"+str(t["id"]), "id"); i >= 0 {
+			templates = append(templates, s.buildTypeRef(s.buildTypes[i]))
+		}
+	}
+	out["templates"] = collection("buildType", templates)
+	return out
+}
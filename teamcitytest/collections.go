@@ -0,0 +1,279 @@
+package teamcitytest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// matches reports whether o is selected by the locator. Dimensions are compared with the fields of the same name,
+// except a dimension without name, such as "X", which is compared with the field named def.
+func matches(l *teamcity.LocatorBuilder, o object, def string) bool {
+	dims := l.Dimensions()
+	if len(dims) == 0 {
+		return false
+	}
+	for _, d := range dims {
+		field := d.Name
+		if field == "" {
+			field = def
+		}
+		if str(o[field]) != d.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupID returns the id selected by the nested locator of a dimension, such as X for "project:(id:X)"
+func lookupID(l *teamcity.LocatorBuilder, name string) (string, bool, error) {
+	sub, ok, err := l.LookupSub(name)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+	id, _ := sub.Lookup("id")
+	return id, true, nil
+}
+
+// find returns the index of the first item of items the locator selects, or -1
+func find(items []object, loc string, def string) int {
+	l, err := teamcity.ParseLocator(loc)
+	if err != nil {
+		return -1
+	}
+	for i, item := range items {
+		if matches(l, item, def) {
+			return i
+		}
+	}
+	return -1
+}
+
+// list reads the items of a collection field, such as {"count": 1, "step": [...]}
+func list(o object, field string, itemField string) []object {
+	collection, ok := o[field].(object)
+	if !ok {
+		return nil
+	}
+	raw, _ := collection[itemField].([]interface{})
+	out := make([]object, 0, len(raw))
+	for _, item := range raw {
+		if obj, ok := item.(object); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// collection builds a collection value such as {"count": 1, "step": [...]}
+func collection(itemField string, items []object) object {
+	raw := make([]interface{}, len(items))
+	for i, item := range items {
+		raw[i] = item
+	}
+	return object{"count": len(items), itemField: raw}
+}
+
+func setList(o object, field string, itemField string, items []object) {
+	o[field] = collection(itemField, items)
+}
+
+// subCollection describes a collection nested in an entity, such as the steps of a build configuration
+type subCollection struct {
+	field     string
+	itemField string
+
+	// idPrefix is used for ids generated for new items, such as "RUNNER_"
+	idPrefix string
+
+	// idOf derives the id of a new item from its content instead, such as the source build configuration of a dependency
+	idOf func(item object) string
+}
+
+// serveSubCollection handles requests to the collection stored in the field of parent, with segments being the path below the collection
+func (s *Server) serveSubCollection(c subCollection, parent object, r *request, segments []string) (interface{}, error) {
+	items := list(parent, c.field, c.itemField)
+
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			return collection(c.itemField, items), nil
+		case http.MethodPost:
+			item, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			s.assignID(c, item, items)
+			setList(parent, c.field, c.itemField, append(items, item))
+			return item, nil
+		case http.MethodPut:
+			body, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			replaced := list(object{c.field: body}, c.field, c.itemField)
+			for i, item := range replaced {
+				s.assignID(c, item, replaced[:i])
+			}
+			setList(parent, c.field, c.itemField, replaced)
+			return parent[c.field], nil
+		}
+		return nil, nil
+	}
+
+	i := find(items, segments[0], "id")
+	if i < 0 {
+		return nil, notFound("No %s found by locator '%s'", c.itemField, segments[0])
+	}
+	if len(segments) > 1 {
+		return nil, nil
+	}
+	switch r.method {
+	case http.MethodGet:
+		return items[i], nil
+	case http.MethodPut:
+		item, err := r.json()
+		if err != nil {
+			return nil, err
+		}
+		item["id"] = items[i]["id"]
+		items[i] = item
+		setList(parent, c.field, c.itemField, items)
+		return item, nil
+	case http.MethodDelete:
+		setList(parent, c.field, c.itemField, append(items[:i], items[i+1:]...))
+		return deleted, nil
+	}
+	return nil, nil
+}
+
+func (s *Server) assignID(c subCollection, item object, existing []object) {
+	normalize(item)
+	if c.idOf != nil {
+		if id := c.idOf(item); id != "" {
+			item["id"] = id
+			return
+		}
+	}
+	if str(item["id"]) != "" && find(existing, "id:"+str(item["id"]), "id") < 0 {
+		return
+	}
+	item["id"] = s.nextID(c.idPrefix)
+}
+
+// normalize drops "disabled" from an item when false, as TeamCity only returns it when set
+func normalize(item object) {
+	if item["disabled"] == false {
+		delete(item, "disabled")
+	}
+}
+
+// serveProperties handles the parameters or settings of an entity, stored as {"property": [...]} in field of parent
+func serveProperties(parent object, field string, r *request, segments []string) (interface{}, error) {
+	props := list(parent, field, "property")
+
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			return collection("property", props), nil
+		case http.MethodPut:
+			body, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			setList(parent, field, "property", list(object{field: body}, field, "property"))
+			return parent[field], nil
+		case http.MethodPost:
+			prop, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			setList(parent, field, "property", setProperty(props, prop))
+			return prop, nil
+		}
+		return nil, nil
+	}
+
+	name := segments[0]
+	i := -1
+	for j, p := range props {
+		if str(p["name"]) == name {
+			i = j
+		}
+	}
+	if len(segments) == 1 && r.method == http.MethodPut {
+		// The property is sent either as JSON, or as its plain text value
+		if strings.HasPrefix(strings.TrimSpace(string(r.body)), "{") {
+			prop, err := r.json()
+			if err != nil {
+				return nil, err
+			}
+			prop["name"] = name
+			setList(parent, field, "property", setProperty(props, prop))
+			return prop, nil
+		}
+		prop := object{"name": name}
+		if i >= 0 {
+			prop = copyObject(props[i])
+		}
+		prop["value"] = string(r.body)
+		setList(parent, field, "property", setProperty(props, prop))
+		return text(string(r.body)), nil
+	}
+	if i < 0 {
+		return nil, notFound("No property with name '%s' is found", name)
+	}
+	if len(segments) > 1 {
+		return nil, nil
+	}
+	switch r.method {
+	case http.MethodGet:
+		return props[i], nil
+	case http.MethodDelete:
+		setList(parent, field, "property", append(props[:i], props[i+1:]...))
+		return deleted, nil
+	}
+	return nil, nil
+}
+
+// setProperty replaces the property with the name of prop, or adds it
+func setProperty(props []object, prop object) []object {
+	for i, p := range props {
+		if str(p["name"]) == str(prop["name"]) {
+			props[i] = prop
+			return props
+		}
+	}
+	return append(props, prop)
+}
+
+// serveTextField handles GET and PUT of a field of o as plain text, such as "/name".
+// kind is the type the text is stored as: "" for a string, "int" or "bool".
+func serveTextField(o object, field string, kind string, r *request) (interface{}, error) {
+	switch r.method {
+	case http.MethodGet:
+		return text(str(o[field])), nil
+	case http.MethodPut:
+		value := string(r.body)
+		switch kind {
+		case "int":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, badRequest("Invalid value '%s' for field '%s'", value, field)
+			}
+			o[field] = n
+		case "bool":
+			b, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return nil, badRequest("Invalid value '%s' for field '%s'", value, field)
+			}
+			o[field] = b
+		default:
+			o[field] = value
+		}
+		return text(value), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,201 @@
+package teamcitytest
+
+import (
+	"net/http"
+)
+
+func (s *Server) routeGroups(r *request) (interface{}, error) {
+	segments := r.segments[1:]
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			out := make([]object, len(s.groups))
+			for i, g := range s.groups {
+				out[i] = s.groupRef(g)
+			}
+			return collection("group", out), nil
+		case http.MethodPost:
+			return s.createGroup(r)
+		}
+		return nil, nil
+	}
+
+	i := find(s.groups, segments[0], "key")
+	if i < 0 {
+		return nil, notFound("No group found by locator '%s'.", segments[0])
+	}
+	group := s.groups[i]
+	if len(segments) == 1 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderGroup(group), nil
+		case http.MethodDelete:
+			s.groups = append(s.groups[:i], s.groups[i+1:]...)
+			for _, g := range s.groups {
+				setList(g, "parent-groups", "group", filter(list(g, "parent-groups", "group"), func(p object) bool {
+					return str(p["key"]) != str(group["key"])
+				}))
+			}
+			return deleted, nil
+		}
+		return nil, nil
+	}
+
+	switch segments[1] {
+	case "name", "description":
+		if len(segments) == 2 {
+			return serveTextField(group, segments[1], "", r)
+		}
+	case "parent-groups":
+		if len(segments) == 2 {
+			return s.serveParentGroups(group, r)
+		}
+	case "roles":
+		return s.serveRoles(group, r, segments[2:])
+	}
+	return nil, nil
+}
+
+func (s *Server) createGroup(r *request) (interface{}, error) {
+	body, err := r.json()
+	if err != nil {
+		return nil, err
+	}
+	key, name := str(body["key"]), str(body["name"])
+	if key == "" || name == "" {
+		return nil, badRequest("Group key and name should be specified")
+	}
+	if find(s.groups, "key:"+key, "key") >= 0 {
+		return nil, badRequest("Group with key \"%s\" already exists", key)
+	}
+
+	group := object{"key": key, "name": name, "description": str(body["description"])}
+	setList(group, "parent-groups", "group", nil)
+	setList(group, "roles", "role", nil)
+
+	s.groups = append(s.groups, group)
+	return s.renderGroup(group), nil
+}
+
+// serveParentGroups handles the parent groups of a group, stored as references holding only their key
+func (s *Server) serveParentGroups(group object, r *request) (interface{}, error) {
+	switch r.method {
+	case http.MethodGet:
+		return s.renderGroup(group)["parent-groups"], nil
+	case http.MethodPut:
+		body, err := r.json()
+		if err != nil {
+			return nil, err
+		}
+		var parents []object
+		for _, ref := range list(object{"parent-groups": body}, "parent-groups", "group") {
+			key := str(ref["key"])
+			if find(s.groups, "key:"+key, "key") < 0 {
+				return nil, notFound("No group found by locator 'key:%s'.", key)
+			}
+			if s.descendsFrom(key, str(group["key"])) {
+				return nil, badRequest("Group '%s' cannot be a parent of its own parent group '%s'", group["key"], key)
+			}
+			parents = append(parents, object{"key": key})
+		}
+		setList(group, "parent-groups", "group", parents)
+		return s.renderGroup(group)["parent-groups"], nil
+	}
+	return nil, nil
+}
+
+// descendsFrom reports whether the group with key is the group with ancestor, or one of its descendants
+func (s *Server) descendsFrom(key string, ancestor string) bool {
+	if key == ancestor {
+		return true
+	}
+	i := find(s.groups, "key:"+key, "key")
+	if i < 0 {
+		return false
+	}
+	for _, parent := range list(s.groups[i], "parent-groups", "group") {
+		if s.descendsFrom(str(parent["key"]), ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveRoles handles the role assignments of a group, at "/roles" and "/roles/{roleId}/{scope}"
+func (s *Server) serveRoles(group object, r *request, segments []string) (interface{}, error) {
+	roles := list(group, "roles", "role")
+	if len(segments) == 0 {
+		if r.method == http.MethodGet {
+			return object{"role": collection("role", roles)["role"]}, nil
+		}
+		return nil, nil
+	}
+	if len(segments) != 2 {
+		return nil, nil
+	}
+
+	roleID, scope := segments[0], segments[1]
+	i := -1
+	for j, role := range roles {
+		if str(role["roleId"]) == roleID && str(role["scope"]) == scope {
+			i = j
+		}
+	}
+	switch r.method {
+	case http.MethodPost:
+		role := object{
+			"roleId": roleID,
+			"scope":  scope,
+			"href":   s.href("userGroups", "key:"+str(group["key"])+"/roles/"+roleID+"/"+scope),
+		}
+		if i < 0 {
+			setList(group, "roles", "role", append(roles, role))
+		}
+		return role, nil
+	case http.MethodGet:
+		if i < 0 {
+			return nil, notFound("Group '%s' does not have role '%s' in scope '%s'", group["key"], roleID, scope)
+		}
+		return roles[i], nil
+	case http.MethodDelete:
+		if i < 0 {
+			return nil, notFound("Group '%s' does not have role '%s' in scope '%s'", group["key"], roleID, scope)
+		}
+		setList(group, "roles", "role", append(roles[:i], roles[i+1:]...))
+		return deleted, nil
+	}
+	return nil, nil
+}
+
+func (s *Server) groupRef(g object) object {
+	return object{
+		"key":         g["key"],
+		"name":        g["name"],
+		"description": g["description"],
+		"href":        s.href("userGroups", "key:"+str(g["key"])),
+	}
+}
+
+func (s *Server) renderGroup(g object) object {
+	out := copyObject(g)
+	for k, v := range s.groupRef(g) {
+		out[k] = v
+	}
+
+	var parents, children []object
+	for _, ref := range list(g, "parent-groups", "group") {
+		if i := find(s.groups, "key:"+str(ref["key"]), "key"); i >= 0 {
+			parents = append(parents, s.groupRef(s.groups[i]))
+		}
+	}
+	for _, other := range s.groups {
+		if find(list(other, "parent-groups", "group"), "key:"+str(g["key"]), "key") >= 0 {
+			children = append(children, s.groupRef(other))
+		}
+	}
+	out["parent-groups"] = collection("group", parents)
+	out["child-groups"] = collection("group", children)
+	out["roles"] = object{"role": collection("role", list(g, "roles", "role"))["role"]}
+	out["users"] = collection("user", nil)
+	return out
+}
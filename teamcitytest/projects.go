@@ -0,0 +1,234 @@
+package teamcitytest
+
+import (
+	"net/http"
+)
+
+var projectFeatures = subCollection{field: "projectFeatures", itemField: "projectFeature", idPrefix: "PROJECT_EXT_"}
+
+func initProject(p object) {
+	if _, ok := p["parameters"]; !ok {
+		setList(p, "parameters", "property", nil)
+	}
+	if _, ok := p["projectFeatures"]; !ok {
+		setList(p, "projectFeatures", "projectFeature", nil)
+	}
+	p["archived"] = p["archived"] == true
+}
+
+func (s *Server) routeProjects(r *request) (interface{}, error) {
+	segments := r.segments[1:]
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			out := make([]object, len(s.projects))
+			for i, p := range s.projects {
+				out[i] = s.projectRef(p)
+			}
+			return collection("project", out), nil
+		case http.MethodPost:
+			return s.createProject(r)
+		}
+		return nil, nil
+	}
+
+	i := find(s.projects, segments[0], "id")
+	if i < 0 {
+		return nil, notFound("No project found by locator '%s'. Project cannot be found by external id '%s'.", segments[0], segments[0])
+	}
+	project := s.projects[i]
+	if len(segments) == 1 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderProject(project), nil
+		case http.MethodDelete:
+			return s.deleteProject(project)
+		}
+		return nil, nil
+	}
+
+	switch segments[1] {
+	case "name", "description":
+		if len(segments) == 2 {
+			return serveTextField(project, segments[1], "", r)
+		}
+	case "archived":
+		if len(segments) == 2 {
+			return serveTextField(project, segments[1], "bool", r)
+		}
+	case "parentProject":
+		if len(segments) == 2 {
+			return s.serveParentProject(project, r)
+		}
+	case "parameters":
+		return serveProperties(project, "parameters", r, segments[2:])
+	case "projectFeatures":
+		return s.serveSubCollection(projectFeatures, project, r, segments[2:])
+	}
+	return nil, nil
+}
+
+func (s *Server) createProject(r *request) (interface{}, error) {
+	body, err := r.json()
+	if err != nil {
+		return nil, err
+	}
+	name := str(body["name"])
+	if name == "" {
+		return nil, badRequest("Project name cannot be empty.")
+	}
+
+	parentID := RootProjectID
+	if id := refID(body["parentProject"]); id != "" {
+		parentID = id
+	} else if id := str(body["parentProjectId"]); id != "" {
+		parentID = id
+	}
+	if find(s.projects, "id:"+parentID, "id") < 0 {
+		return nil, notFound("No project found by locator 'id:%s'.", parentID)
+	}
+	for _, p := range s.projects {
+		if str(p["parentProjectId"]) == parentID && str(p["name"]) == name {
+			return nil, badRequest("Project with name \"%s\" already exists in the parent project", name)
+		}
+	}
+
+	id := str(body["id"])
+	if id == "" {
+		id = uniqueID(parentID, name, func(id string) bool { return find(s.projects, "id:"+id, "id") >= 0 })
+	} else if find(s.projects, "id:"+id, "id") >= 0 {
+		return nil, badRequest("Project ID \"%s\" is already used by another project", id)
+	}
+
+	project := object{
+		"id":              id,
+		"name":            name,
+		"description":     str(body["description"]),
+		"parentProjectId": parentID,
+		"parameters":      body["parameters"],
+	}
+	if body["parameters"] == nil {
+		delete(project, "parameters")
+	}
+	initProject(project)
+	s.projects = append(s.projects, project)
+	return s.renderProject(project), nil
+}
+
+func (s *Server) serveParentProject(project object, r *request) (interface{}, error) {
+	switch r.method {
+	case http.MethodGet:
+		if parent := s.project(str(project["parentProjectId"])); parent != nil {
+			return s.projectRef(parent), nil
+		}
+		return nil, notFound("Project '%s' has no parent project", project["id"])
+	case http.MethodPut:
+		body, err := r.json()
+		if err != nil {
+			return nil, err
+		}
+		parent := s.project(refID(body))
+		if parent == nil {
+			return nil, notFound("No project found by locator 'id:%s'.", refID(body))
+		}
+		for id := str(parent["id"]); id != ""; id = str(s.project(id)["parentProjectId"]) {
+			if id == str(project["id"]) {
+				return nil, badRequest("Cannot move project '%s' into its own subproject", project["id"])
+			}
+		}
+		project["parentProjectId"] = parent["id"]
+		return s.renderProject(project), nil
+	}
+	return nil, nil
+}
+
+func (s *Server) deleteProject(project object) (interface{}, error) {
+	if str(project["id"]) == RootProjectID {
+		return nil, badRequest("Root project cannot be deleted")
+	}
+
+	// Delete the project with its subprojects, and everything they hold
+	removed := map[string]bool{str(project["id"]): true}
+	for changed := true; changed; {
+		changed = false
+		for _, p := range s.projects {
+			if !removed[str(p["id"])] && removed[str(p["parentProjectId"])] {
+				removed[str(p["id"])] = true
+				changed = true
+			}
+		}
+	}
+	s.projects = filter(s.projects, func(p object) bool { return !removed[str(p["id"])] })
+	s.buildTypes = filter(s.buildTypes, func(b object) bool { return !removed[str(b["projectId"])] })
+	s.vcsRoots = filter(s.vcsRoots, func(v object) bool { return !removed[refID(v["project"])] })
+	for _, pool := range s.agentPools {
+		setList(pool, "projects", "project", filter(list(pool, "projects", "project"), func(p object) bool { return !removed[str(p["id"])] }))
+	}
+	return deleted, nil
+}
+
+// project returns the project with given id, or nil
+func (s *Server) project(id string) object {
+	if i := find(s.projects, "id:"+id, "id"); i >= 0 {
+		return s.projects[i]
+	}
+	return nil
+}
+
+func (s *Server) projectRef(p object) object {
+	ref := object{
+		"id":          p["id"],
+		"name":        p["name"],
+		"description": p["description"],
+		"href":        s.href("projects", "id:"+str(p["id"])),
+		"webUrl":      s.URL + "/project.html?projectId=" + str(p["id"]),
+	}
+	if parent := str(p["parentProjectId"]); parent != "" {
+		ref["parentProjectId"] = parent
+	}
+	return ref
+}
+
+func (s *Server) renderProject(p object) object {
+	out := copyObject(p)
+	for k, v := range s.projectRef(p) {
+		out[k] = v
+	}
+	if parent := s.project(str(p["parentProjectId"])); parent != nil {
+		out["parentProject"] = s.projectRef(parent)
+	}
+
+	var buildTypes, templates []object
+	for _, b := range s.buildTypes {
+		if str(b["projectId"]) != str(p["id"]) {
+			continue
+		}
+		if b["templateFlag"] == true {
+			templates = append(templates, s.buildTypeRef(b))
+		} else {
+			buildTypes = append(buildTypes, s.buildTypeRef(b))
+		}
+	}
+	out["buildTypes"] = collection("buildType", buildTypes)
+	out["templates"] = collection("buildType", templates)
+
+	var children []object
+	for _, child := range s.projects {
+		if str(child["parentProjectId"]) == str(p["id"]) {
+			children = append(children, s.projectRef(child))
+		}
+	}
+	out["projects"] = collection("project", children)
+	return out
+}
+
+// filter returns the items keep returns true for
+func filter(items []object, keep func(object) bool) []object {
+	var out []object
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,215 @@
+package teamcitytest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// Interaction is a request with the response the server gave it, as stored in a golden file
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a scrubbed request. URL holds only the path and query, not the address of the server.
+type RecordedRequest struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     string      `json:"body,omitempty"`
+	BodyHash string      `json:"bodyHash,omitempty"`
+}
+
+// RecordedResponse is a scrubbed response
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// key identifies the requests a recorded response answers
+func (r RecordedRequest) key() string {
+	return r.Method + " " + r.URL + " " + r.BodyHash
+}
+
+// Recorder is an http.RoundTripper recording the requests it forwards and their responses
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a Recorder forwarding requests to transport, or http.DefaultTransport if nil.
+// Call Save once done to write the golden file at path.
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{path: path, transport: transport}
+}
+
+// Client returns an HTTP client recording through r
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip forwards req, and records it with its response. The response body is read whole before being returned.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: scrubRequest(req, body),
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     teamcity.RedactHeaders(resp.Header),
+			Body:       teamcity.RedactBody(req, respBody),
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Interactions returns what was recorded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes the recorded interactions to the golden file, creating its directory if needed
+func (r *Recorder) Save() error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Replayer is an http.RoundTripper answering requests with the responses recorded for them
+type Replayer struct {
+	mu        sync.Mutex
+	responses map[string][]RecordedResponse
+}
+
+// NewReplayer returns a Replayer serving the interactions of the golden file at path
+func NewReplayer(path string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("teamcitytest: reading %s: %s", path, err)
+	}
+	return NewReplayerFromInteractions(interactions), nil
+}
+
+// NewReplayerFromInteractions returns a Replayer serving the given interactions, such as those of a Recorder
+func NewReplayerFromInteractions(interactions []Interaction) *Replayer {
+	r := &Replayer{responses: map[string][]RecordedResponse{}}
+	for _, i := range interactions {
+		key := i.Request.key()
+		r.responses[key] = append(r.responses[key], i.Response)
+	}
+	return r
+}
+
+// Client returns an HTTP client replaying through r
+func (r *Replayer) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip answers req with a recorded response. Identical requests get the responses recorded for them in order,
+// the last one being repeated. Requests that were not recorded fail.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	key := scrubRequest(req, body).key()
+
+	r.mu.Lock()
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("teamcitytest: no recorded response for %s", key)
+	}
+	recorded := responses[0]
+	if len(responses) > 1 {
+		r.responses[key] = responses[1:]
+	}
+	r.mu.Unlock()
+
+	header := recorded.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// scrubRequest records req, hashing its scrubbed body so that requests differing only by secrets are matched
+func scrubRequest(req *http.Request, body []byte) RecordedRequest {
+	out := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Header: teamcity.RedactHeaders(req.Header),
+		Body:   teamcity.RedactBody(req, body),
+	}
+	if out.Body != "" {
+		sum := sha256.Sum256([]byte(out.Body))
+		out.BodyHash = hex.EncodeToString(sum[:])
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package teamcitytest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/cvbarros/go-teamcity/teamcitytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createSecretProject creates a project with a password parameter, then renames it, reading it back after each change
+func createSecretProject(t *testing.T, client *teamcity.Client, password string) *teamcity.Project {
+	project, err := teamcity.NewProject("Recorded", "", "")
+	require.NoError(t, err)
+	project.Parameters.AddOrReplaceValue(teamcity.ParameterTypes.Configuration, "deploy.password", password)
+	created, err := client.Projects.Create(project)
+	require.NoError(t, err)
+
+	created.Name = "Renamed"
+	updated, err := client.Projects.Update(created)
+	require.NoError(t, err)
+	return updated
+}
+
+func Test_RecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "teamcitytest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "testdata", "project.json")
+
+	server := teamcitytest.NewServer()
+	recorder := teamcitytest.NewRecorder(golden, nil)
+	client, err := teamcity.NewClientWithAddress(teamcity.BasicAuth("admin", "s3cr3t"), server.URL, recorder.Client())
+	require.NoError(t, err)
+	recorded := createSecretProject(t, client, "hunter2")
+	server.Close()
+	require.NoError(t, recorder.Save())
+
+	data, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.Contains(t, string(data), teamcity.Redacted)
+
+	replayer, err := teamcitytest.NewReplayer(golden)
+	require.NoError(t, err)
+	offline, err := teamcity.NewClientWithAddress(teamcity.BasicAuth("admin", "other"), "http://teamcity.invalid", replayer.Client())
+	require.NoError(t, err)
+	replayed := createSecretProject(t, offline, "another password")
+
+	assert.Equal(t, recorded.ID, replayed.ID)
+	assert.Equal(t, "Renamed", replayed.Name)
+}
+
+func Test_ReplayMatchesBody(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	recorder := teamcitytest.NewRecorder("", nil)
+	client, err := teamcity.NewClientWithAddress(teamcity.BasicAuth("admin", "admin"), server.URL, recorder.Client())
+	require.NoError(t, err)
+	_, err = client.Groups.Create(&teamcity.Group{Key: "DEVS", Name: "Developers"})
+	require.NoError(t, err)
+
+	replayer := teamcitytest.NewReplayerFromInteractions(recorder.Interactions())
+	offline, err := teamcity.NewClientWithAddress(teamcity.BasicAuth("admin", "admin"), server.URL, replayer.Client())
+	require.NoError(t, err)
+
+	group, err := offline.Groups.Create(&teamcity.Group{Key: "DEVS", Name: "Developers"})
+	require.NoError(t, err)
+	assert.Equal(t, "DEVS", group.Key)
+
+	_, err = offline.Groups.Create(&teamcity.Group{Key: "OPS", Name: "Operations"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded response for POST")
+}
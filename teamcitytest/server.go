@@ -0,0 +1,303 @@
+// Package teamcitytest provides an in-memory fake TeamCity server, for testing code that uses teamcity.Client without a live server.
+//
+// The fake implements the REST endpoints the client uses for projects, build configurations and templates with their steps,
+// triggers, features, dependencies, agent requirements and VCS root entries, VCS roots, project features, agent pools,
+// user groups and their role assignments. State is kept per Server until it is closed:
+//
+//	server := teamcitytest.NewServer()
+//	defer server.Close()
+//
+//	client := server.Client()
+//	project, _ := teamcity.NewProject("Test", "", "")
+//	created, err := client.Projects.Create(project)
+//
+// The fake does not run builds, evaluate permissions or apply settings inherited from templates.
+// Requests to endpoints it does not implement are answered with 501 Not Implemented.
+//
+// As an alternative to the fake, requests and responses can be recorded in golden files, which a Recorder writes while
+// talking to a live server and a Replayer serves back without one. Both are http.RoundTrippers, given to the client
+// through the httpClient argument of teamcity.NewClientWithAddress:
+//
+//	recorder := teamcitytest.NewRecorder("testdata/projects.json", nil)
+//	client, _ := teamcity.NewClientWithAddress(auth, address, recorder.Client())
+//	...
+//	err := recorder.Save()
+//
+// and later, offline:
+//
+//	replayer, err := teamcitytest.NewReplayer("testdata/projects.json")
+//	client, _ := teamcity.NewClientWithAddress(auth, "http://teamcity", replayer.Client())
+//
+// Secrets are scrubbed before anything is written, with teamcity.RedactHeaders and teamcity.RedactBody.
+// Requests are matched by method, path with query, and a hash of their scrubbed body, so that a replayed test may use
+// other credentials or another server address than the recorded one, but must use the same kind of authentication.
+package teamcitytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+)
+
+// RootProjectID is the id of the project created with every Server, all other projects descend from
+const RootProjectID = "_Root"
+
+// Server is a fake TeamCity server listening on a local address
+type Server struct {
+	// URL is the address of the server, to be given to teamcity.NewClientWithAddress
+	URL string
+
+	server *httptest.Server
+
+	mu         sync.Mutex
+	counters   map[string]int
+	projects   []object
+	buildTypes []object
+	vcsRoots   []object
+	agentPools []object
+	groups     []object
+}
+
+// NewServer starts a fake TeamCity server with only the root project, the default agent pool and the All Users group.
+// It must be closed once done.
+func NewServer() *Server {
+	s := &Server{
+		counters: map[string]int{},
+		projects: []object{
+			{"id": RootProjectID, "name": "<Root project>", "description": "Contains all other projects"},
+		},
+		agentPools: []object{
+			{"id": 0, "name": "Default"},
+		},
+		groups: []object{
+			{"key": teamcity.AllUsersGroupKey, "name": "All Users", "description": "Contains all TeamCity users"},
+		},
+	}
+	for _, p := range s.projects {
+		initProject(p)
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server, discarding its state
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client for the server, authenticated as an administrator
+func (s *Server) Client() *teamcity.Client {
+	client, err := teamcity.NewClientWithAddress(teamcity.BasicAuth("admin", "admin"), s.URL, s.server.Client())
+	if err != nil {
+		panic(fmt.Sprintf("teamcitytest: creating client: %s", err))
+	}
+	return client
+}
+
+// object is a JSON object, as sent and stored by the fake
+type object = map[string]interface{}
+
+// text is a plain text response body
+type text string
+
+// request is a REST API request, with its path split into unescaped segments below "/app/rest/"
+type request struct {
+	method   string
+	segments []string
+	query    url.Values
+	body     []byte
+}
+
+// json decodes the body of the request as a JSON object
+func (r *request) json() (object, error) {
+	out := object{}
+	if len(bytes.TrimSpace(r.body)) == 0 {
+		return out, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(r.body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&out); err != nil {
+		return nil, badRequest("Could not parse the request body as JSON: %s", err)
+	}
+	return out, nil
+}
+
+// apiError is an error response, worded like TeamCity's own
+type apiError struct {
+	status int
+	text   string
+}
+
+func (e *apiError) Error() string {
+	return e.text
+}
+
+func newAPIError(status int, exception string, format string, args ...interface{}) error {
+	return &apiError{
+		status: status,
+		text: fmt.Sprintf("Responding with error, status code: %d (%s).\nDetails: jetbrains.buildServer.server.rest.errors.%s: %s",
+			status, http.StatusText(status), exception, fmt.Sprintf(format, args...)),
+	}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return newAPIError(http.StatusNotFound, "NotFoundException", format, args...)
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return newAPIError(http.StatusBadRequest, "BadRequestException", format, args...)
+}
+
+func notImplemented(r *request) error {
+	return &apiError{
+		status: http.StatusNotImplemented,
+		text:   fmt.Sprintf("teamcitytest: %s /app/rest/%s is not implemented by the fake server", r.method, strings.Join(r.segments, "/")),
+	}
+}
+
+// deleted is the result of a successful DELETE, answered with 204 No Content
+var deleted = &struct{}{}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const root = "/app/rest/"
+	path := r.URL.EscapedPath()
+	i := strings.Index(path, root)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &request{method: r.Method, query: r.URL.Query(), body: body}
+	for _, segment := range strings.Split(strings.Trim(path[i+len(root):], "/"), "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.segments = append(req.segments, unescaped)
+	}
+
+	s.mu.Lock()
+	out, err := s.route(req)
+	s.mu.Unlock()
+
+	switch v := out.(type) {
+	case nil:
+		if err == nil {
+			err = notImplemented(req)
+		}
+	case text:
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(v))
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(*apiError); ok {
+			status = apiErr.status
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if out == deleted {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (s *Server) route(r *request) (interface{}, error) {
+	switch r.segments[0] {
+	case "server":
+		if len(r.segments) == 1 && r.method == http.MethodGet {
+			return object{"version": "2019.2.2 (build 72059)", "versionMajor": 2019, "versionMinor": 2, "buildNumber": "72059", "webUrl": s.URL}, nil
+		}
+	case "projects":
+		return s.routeProjects(r)
+	case "buildTypes":
+		return s.routeBuildTypes(r)
+	case "vcs-roots":
+		return s.routeVcsRoots(r)
+	case "agentPools":
+		return s.routeAgentPools(r)
+	case "userGroups":
+		return s.routeGroups(r)
+	}
+	return nil, nil
+}
+
+func (s *Server) href(collection string, locator string) string {
+	return "/app/rest/" + collection + "/" + locator
+}
+
+// nextID returns prefix followed by a number unique within the server, such as "RUNNER_1"
+func (s *Server) nextID(prefix string) string {
+	s.counters[prefix]++
+	return fmt.Sprintf("%s%d", prefix, s.counters[prefix])
+}
+
+// uniqueID derives an id from the name of an entity like TeamCity does, "My project" becoming "MyProject" under the root
+// project and "Parent_MyProject" elsewhere, and makes it unique among existing
+func uniqueID(prefix string, name string, exists func(id string) bool) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	base := b.String()
+	if base == "" {
+		base = "Id"
+	}
+	if prefix != "" && prefix != RootProjectID {
+		base = prefix + "_" + base
+	}
+
+	id := base
+	for n := 2; exists(id); n++ {
+		id = fmt.Sprintf("%s%d", base, n)
+	}
+	return id
+}
+
+func str(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// copyObject returns a shallow copy of o, so that computed fields can be added for a response without storing them
+func copyObject(o object) object {
+	out := make(object, len(o))
+	for k, v := range o {
+		out[k] = v
+	}
+	return out
+}
+
+// refID returns the id of a reference such as {"id": "X"}, or the empty string
+func refID(v interface{}) string {
+	if ref, ok := v.(object); ok {
+		return str(ref["id"])
+	}
+	return ""
+}
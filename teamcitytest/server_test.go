@@ -0,0 +1,362 @@
+package teamcitytest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cvbarros/go-teamcity/teamcity"
+	"github.com/cvbarros/go-teamcity/teamcitytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T) (*teamcity.Client, func()) {
+	server := teamcitytest.NewServer()
+	return server.Client(), server.Close
+}
+
+func createProject(t *testing.T, client *teamcity.Client, name string) *teamcity.Project {
+	project, err := teamcity.NewProject(name, "A test project", "")
+	require.NoError(t, err)
+	created, err := client.Projects.Create(project)
+	require.NoError(t, err)
+	return created
+}
+
+func Test_ServerProjects(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+
+	project, err := teamcity.NewProject("My Project", "A test project", "")
+	require.NoError(t, err)
+	project.Parameters.AddOrReplaceValue(teamcity.ParameterTypes.Configuration, "env", "test")
+	created, err := client.Projects.Create(project)
+	require.NoError(t, err)
+	assert.Equal(t, "MyProject", created.ID)
+	assert.Equal(t, "A test project", created.Description)
+	assert.Equal(t, teamcitytest.RootProjectID, created.ParentProjectID)
+
+	created.Name = "Renamed"
+	updated, err := client.Projects.Update(created)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.EqualValues(t, 1, updated.Parameters.Count)
+
+	child, err := teamcity.NewProject("Child", "", created.ID)
+	require.NoError(t, err)
+	child, err = client.Projects.Create(child)
+	require.NoError(t, err)
+	assert.Equal(t, "MyProject_Child", child.ID)
+
+	require.NoError(t, client.Projects.Delete(created.ID))
+	_, err = client.Projects.GetByID(child.ID)
+	assert.True(t, teamcity.IsNotFound(err), "deleting a project should delete its subprojects")
+}
+
+func Test_ServerProjectLocatorDecodesBase64(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	created := createProject(t, server.Client(), "Legacy (v1")
+
+	locator := teamcity.NewLocatorBuilder().Name("Legacy (v1")
+	require.Contains(t, locator.String(), "$base64:")
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/httpAuth/app/rest/projects/"+string(locator.Locator()), nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth("admin", "admin")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var project struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&project))
+	assert.Equal(t, created.ID, project.ID)
+}
+
+func Test_ServerBuildTypes(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+	project := createProject(t, client, "Build")
+
+	buildType, err := teamcity.NewBuildType(project.ID, "Pull Request")
+	require.NoError(t, err)
+	created, err := client.BuildTypes.Create(project.ID, buildType)
+	require.NoError(t, err)
+	assert.Equal(t, "Build_PullRequest", created.ID)
+
+	step, err := teamcity.NewStepCommandLineScript("build", "make")
+	require.NoError(t, err)
+	_, err = client.BuildTypes.AddStep(created.ID, step)
+	require.NoError(t, err)
+	steps, err := client.BuildTypes.GetSteps(created.ID)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "build", steps[0].GetName())
+
+	trigger, err := teamcity.NewTriggerVcs([]string{"+:*"}, []string{})
+	require.NoError(t, err)
+	addedTrigger, err := client.TriggerService(created.ID).AddTrigger(trigger)
+	require.NoError(t, err)
+	retrievedTrigger, err := client.TriggerService(created.ID).GetByID(addedTrigger.ID())
+	require.NoError(t, err)
+	assert.Equal(t, addedTrigger.ID(), retrievedTrigger.ID())
+
+	source, err := teamcity.NewBuildType(project.ID, "Source")
+	require.NoError(t, err)
+	sourceRef, err := client.BuildTypes.Create(project.ID, source)
+	require.NoError(t, err)
+	dep, err := client.DependencyService(created.ID).AddSnapshotDependency(teamcity.NewSnapshotDependency(sourceRef.ID))
+	require.NoError(t, err)
+	assert.Equal(t, sourceRef.ID, dep.ID)
+
+	retrieved, err := client.BuildTypes.GetByID(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, project.ID, retrieved.ProjectID)
+	assert.Len(t, retrieved.Steps, 1)
+}
+
+func Test_ServerBuildTypeFilter(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	parent := createProject(t, client, "Parent")
+	childProject, err := teamcity.NewProject("Child", "", parent.ID)
+	require.NoError(t, err)
+	child, err := client.Projects.Create(childProject)
+	require.NoError(t, err)
+
+	template, err := teamcity.NewBuildTypeTemplate(child.ID, "Base")
+	require.NoError(t, err)
+	baseRef, err := client.BuildTypes.Create(child.ID, template)
+	require.NoError(t, err)
+
+	build, err := teamcity.NewBuildType(parent.ID, "Build")
+	require.NoError(t, err)
+	build.Parameters.AddOrReplaceValue(teamcity.ParameterTypes.Configuration, "env", "prod")
+	build.Parameters.AddOrReplaceValue(teamcity.ParameterTypes.Configuration, "label", "release (1")
+	buildRef, err := client.BuildTypes.Create(parent.ID, build)
+	require.NoError(t, err)
+	opts, err := teamcity.NewGitVcsRootOptionsDefaults("refs/heads/master", "https://github.com/cvbarros/go-teamcity")
+	require.NoError(t, err)
+	root, err := teamcity.NewGitVcsRoot(parent.ID, "Repository", opts)
+	require.NoError(t, err)
+	rootRef, err := client.VcsRoots.Create(parent.ID, root)
+	require.NoError(t, err)
+	require.NoError(t, client.BuildTypes.AttachVcsRoot(buildRef.ID, rootRef))
+
+	deploy, err := teamcity.NewBuildType(child.ID, "Deploy")
+	require.NoError(t, err)
+	deploy.Templates = &teamcity.Templates{Count: 1, Items: []*teamcity.BuildTypeReference{{ID: baseRef.ID}}}
+	deployRef, err := client.BuildTypes.Create(child.ID, deploy)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/httpAuth/app/rest/buildTypes/"+deployRef.ID+"/paused", strings.NewReader("true"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	req.SetBasicAuth("admin", "admin")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	yes, no := true, false
+	cases := []struct {
+		filter   *teamcity.BuildTypeFilter
+		expected []string
+	}{
+		{nil, []string{baseRef.ID, buildRef.ID, deployRef.ID}},
+		{&teamcity.BuildTypeFilter{ProjectID: parent.ID}, []string{buildRef.ID}},
+		{&teamcity.BuildTypeFilter{ProjectID: parent.ID, Recursive: true}, []string{baseRef.ID, buildRef.ID, deployRef.ID}},
+		{&teamcity.BuildTypeFilter{TemplateFlag: &yes}, []string{baseRef.ID}},
+		{&teamcity.BuildTypeFilter{TemplateFlag: &no}, []string{buildRef.ID, deployRef.ID}},
+		{&teamcity.BuildTypeFilter{TemplateFlag: &no, Paused: &yes}, []string{deployRef.ID}},
+		{&teamcity.BuildTypeFilter{VcsRootID: rootRef.ID}, []string{buildRef.ID}},
+		{&teamcity.BuildTypeFilter{TemplateID: baseRef.ID}, []string{deployRef.ID}},
+		{&teamcity.BuildTypeFilter{ParameterName: "env"}, []string{buildRef.ID}},
+		{&teamcity.BuildTypeFilter{ParameterName: "env", ParameterValue: "test"}, nil},
+		{&teamcity.BuildTypeFilter{ParameterName: "label", ParameterValue: "release (1"}, []string{buildRef.ID}},
+	}
+	for i, c := range cases {
+		refs, err := client.BuildTypes.List(c.filter)
+		require.NoError(t, err)
+		var ids []string
+		for _, ref := range refs {
+			ids = append(ids, ref.ID)
+		}
+		assert.Equal(t, c.expected, ids, "case %d", i)
+	}
+
+	selected, err := client.BuildTypes.ListFields(&teamcity.BuildTypeFilter{Paused: &yes}, teamcity.NewFields("id", "paused"))
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.True(t, selected[0].Paused)
+	assert.Empty(t, selected[0].Name)
+
+	parameter := func(name, value string) string {
+		return teamcity.NewLocatorBuilder().Name(name).Dimension("value", value).String()
+	}
+	count := func(locator *teamcity.LocatorBuilder) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/httpAuth/app/rest/buildTypes?locator="+string(locator.Locator()), nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+		req.SetBasicAuth("admin", "admin")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var list struct {
+			Count int `json:"count"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		return list.Count
+	}
+	both := teamcity.NewLocatorBuilder().Dimension("parameter", parameter("env", "prod")).Dimension("parameter", parameter("label", "release (1"))
+	assert.Equal(t, 1, count(both))
+	mismatch := teamcity.NewLocatorBuilder().Dimension("parameter", parameter("env", "test")).Dimension("parameter", parameter("label", "release (1"))
+	assert.Equal(t, 0, count(mismatch))
+}
+
+func Test_ServerVcsRoots(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+	project := createProject(t, client, "Vcs")
+
+	opts, err := teamcity.NewGitVcsRootOptionsDefaults("refs/heads/master", "https://github.com/cvbarros/go-teamcity")
+	require.NoError(t, err)
+	root, err := teamcity.NewGitVcsRoot(project.ID, "Repository", opts)
+	require.NoError(t, err)
+	created, err := client.VcsRoots.Create(project.ID, root)
+	require.NoError(t, err)
+	assert.Equal(t, "Vcs_Repository", created.ID)
+
+	retrieved, err := client.VcsRoots.GetByID(created.ID)
+	require.NoError(t, err)
+	git := retrieved.(*teamcity.GitVcsRoot)
+	assert.Equal(t, "https://github.com/cvbarros/go-teamcity", git.Options.FetchURL)
+
+	git.Options.DefaultBranch = "refs/heads/main"
+	git.SetName("Renamed")
+	_, err = client.VcsRoots.Update(git)
+	require.NoError(t, err)
+
+	retrieved, err = client.VcsRoots.GetByID(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", retrieved.Name())
+	assert.Equal(t, "refs/heads/main", retrieved.(*teamcity.GitVcsRoot).Options.DefaultBranch)
+
+	require.NoError(t, client.VcsRoots.Delete(created.ID))
+	_, err = client.VcsRoots.GetByID(created.ID)
+	assert.Error(t, err)
+}
+
+func Test_ServerProjectFeatures(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+	project := createProject(t, client, "Settings")
+
+	service := client.ProjectFeatureService(project.ID)
+	created, err := service.Create(teamcity.NewProjectFeatureVersionedSettings(project.ID, teamcity.ProjectFeatureVersionedSettingsOptions{
+		Format:        teamcity.VersionedSettingsFormatKotlin,
+		BuildSettings: teamcity.VersionedSettingsBuildSettingsPreferVcs,
+	}))
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID())
+
+	retrieved, err := service.GetByType("versionedSettings")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID(), retrieved.ID())
+
+	require.NoError(t, service.Delete(created.ID()))
+	features, err := service.Get()
+	require.NoError(t, err)
+	assert.Empty(t, features)
+}
+
+func Test_ServerAgentPools(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+	project := createProject(t, client, "Pooled")
+
+	pool, err := client.AgentPools.Create(teamcity.CreateAgentPool{Name: "Linux"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pool.Id)
+
+	require.NoError(t, client.AgentPools.AssignProject(pool.Id, project.ID))
+	pools, err := client.AgentPools.ListForProject(project.ID)
+	require.NoError(t, err)
+	require.Len(t, pools.AgentPools, 1)
+	assert.Equal(t, "Linux", pools.AgentPools[0].Name)
+
+	require.NoError(t, client.AgentPools.UnassignProject(pool.Id, project.ID))
+	retrieved, err := client.AgentPools.GetByName("Linux")
+	require.NoError(t, err)
+	assert.Empty(t, retrieved.Projects.Project)
+}
+
+func Test_ServerGroupsAndRoles(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+
+	group, err := teamcity.NewGroup("DEVS", "Developers", "")
+	require.NoError(t, err)
+	_, err = client.Groups.Create(group)
+	require.NoError(t, err)
+	child, err := teamcity.NewGroup("JUNIORS", "Juniors", "")
+	require.NoError(t, err)
+	_, err = client.Groups.Create(child)
+	require.NoError(t, err)
+
+	_, err = client.Groups.SetParentGroups("JUNIORS", []string{"DEVS"})
+	require.NoError(t, err)
+	children, err := client.Groups.GetChildGroups("DEVS")
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, "JUNIORS", children[0].Key)
+
+	assignment, err := teamcity.NewGroupRoleAssignment("DEVS", "PROJECT_VIEWER", "g")
+	require.NoError(t, err)
+	_, err = client.RoleAssignments.AssignToGroup(assignment)
+	require.NoError(t, err)
+	roles, err := client.RoleAssignments.GetAllForGroup(group)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "PROJECT_VIEWER", roles[0].RoleID)
+
+	require.NoError(t, client.RoleAssignments.UnassignFromGroup(assignment))
+	_, err = client.RoleAssignments.GetForGroup(assignment)
+	assert.True(t, teamcity.IsNotFound(err))
+}
+
+func Test_ServerGroupUpdateKeepsDescriptionWhenEmpty(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+
+	group, err := teamcity.NewGroup("DEVS", "Developers", "All developers")
+	require.NoError(t, err)
+	_, err = client.Groups.Create(group)
+	require.NoError(t, err)
+
+	updated, err := client.Groups.Update(&teamcity.Group{Key: "DEVS", Name: "Engineers"})
+	require.NoError(t, err)
+	assert.Equal(t, "Engineers", updated.Name)
+	assert.Equal(t, "All developers", updated.Description)
+
+	updated, err = client.Groups.Update(&teamcity.Group{Key: "DEVS", Name: "Engineers", Description: "All engineers"})
+	require.NoError(t, err)
+	assert.Equal(t, "All engineers", updated.Description)
+}
+
+func Test_ServerStatePerInstance(t *testing.T) {
+	client, done := newClient(t)
+	defer done()
+	createProject(t, client, "Only Here")
+
+	other, otherDone := newClient(t)
+	defer otherDone()
+	_, err := other.Projects.GetByID("OnlyHere")
+
+	assert.True(t, teamcity.IsNotFound(err))
+}
@@ -0,0 +1,129 @@
+package teamcitytest
+
+import (
+	"net/http"
+)
+
+func (s *Server) routeVcsRoots(r *request) (interface{}, error) {
+	segments := r.segments[1:]
+	if len(segments) == 0 {
+		switch r.method {
+		case http.MethodGet:
+			out := make([]object, len(s.vcsRoots))
+			for i, v := range s.vcsRoots {
+				out[i] = s.vcsRootRef(v)
+			}
+			return collection("vcs-root", out), nil
+		case http.MethodPost:
+			return s.createVcsRoot(r)
+		}
+		return nil, nil
+	}
+
+	i := find(s.vcsRoots, segments[0], "id")
+	if i < 0 {
+		return nil, notFound("No VCS root found by locator '%s'.", segments[0])
+	}
+	vcsRoot := s.vcsRoots[i]
+	if len(segments) == 1 {
+		switch r.method {
+		case http.MethodGet:
+			return s.renderVcsRoot(vcsRoot), nil
+		case http.MethodDelete:
+			s.vcsRoots = append(s.vcsRoots[:i], s.vcsRoots[i+1:]...)
+			return deleted, nil
+		}
+		return nil, nil
+	}
+
+	switch segments[1] {
+	case "properties":
+		return serveProperties(vcsRoot, "properties", r, segments[2:])
+	case "name":
+		if len(segments) == 2 {
+			return serveTextField(vcsRoot, "name", "", r)
+		}
+	case "modificationCheckInterval":
+		if len(segments) == 2 {
+			return serveTextField(vcsRoot, "modificationCheckInterval", "int", r)
+		}
+	case "projectId":
+		if len(segments) == 2 {
+			return s.serveVcsRootProject(vcsRoot, r)
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) createVcsRoot(r *request) (interface{}, error) {
+	body, err := r.json()
+	if err != nil {
+		return nil, err
+	}
+	name := str(body["name"])
+	if name == "" {
+		return nil, badRequest("When creating a VCS root, non empty name should be specified")
+	}
+	projectID := refID(body["project"])
+	if s.project(projectID) == nil {
+		return nil, notFound("No project found by locator 'id:%s'.", projectID)
+	}
+
+	id := str(body["id"])
+	exists := func(id string) bool { return find(s.vcsRoots, "id:"+id, "id") >= 0 }
+	if id == "" {
+		id = uniqueID(projectID, name, exists)
+	} else if exists(id) {
+		return nil, badRequest("VCS root with id \"%s\" already exists", id)
+	}
+
+	vcsRoot := object{
+		"id":      id,
+		"name":    name,
+		"vcsName": str(body["vcsName"]),
+		"project": object{"id": projectID},
+	}
+	if v, ok := body["modificationCheckInterval"]; ok {
+		vcsRoot["modificationCheckInterval"] = v
+	}
+	vcsRoot["properties"] = collection("property", list(body, "properties", "property"))
+
+	s.vcsRoots = append(s.vcsRoots, vcsRoot)
+	return s.renderVcsRoot(vcsRoot), nil
+}
+
+// serveVcsRootProject handles the project of a VCS root as plain text, the VCS root being moved on PUT
+func (s *Server) serveVcsRootProject(vcsRoot object, r *request) (interface{}, error) {
+	switch r.method {
+	case http.MethodGet:
+		return text(refID(vcsRoot["project"])), nil
+	case http.MethodPut:
+		projectID := string(r.body)
+		if s.project(projectID) == nil {
+			return nil, notFound("No project found by locator 'id:%s'.", projectID)
+		}
+		vcsRoot["project"] = object{"id": projectID}
+		return text(projectID), nil
+	}
+	return nil, nil
+}
+
+func (s *Server) vcsRootRef(v object) object {
+	ref := object{
+		"id":   v["id"],
+		"name": v["name"],
+		"href": s.href("vcs-roots", "id:"+str(v["id"])),
+	}
+	if project := s.project(refID(v["project"])); project != nil {
+		ref["project"] = s.projectRef(project)
+	}
+	return ref
+}
+
+func (s *Server) renderVcsRoot(v object) object {
+	out := copyObject(v)
+	for k, value := range s.vcsRootRef(v) {
+		out[k] = value
+	}
+	return out
+}